@@ -15,6 +15,8 @@ import (
 	"github.com/qoentz/evedict/internal/registry"
 )
 
+const embeddingBackfillLimit = 1000
+
 func main() {
 	systemConfig, err := config.ConfigureSystem()
 	if err != nil {
@@ -27,13 +29,37 @@ func main() {
 	}
 	defer func(db *sqlx.DB) { _ = db.Close() }(database)
 
-	reg := registry.NewRegistry(systemConfig, database)
+	reg, err := registry.NewRegistry(systemConfig, database)
+	if err != nil {
+		log.Fatalf("Error initializing registry: %v", err)
+	}
 
 	httpServer := server.ServeHTTP(server.InitRouter(reg))
 
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Embed forecasts saved before embeddings were enabled, so they show up
+	// as semantic related forecasts
+	go func() {
+		n, err := reg.ForecastService.BackfillEmbeddings(backgroundCtx, embeddingBackfillLimit)
+		if err != nil {
+			log.Printf("Error backfilling forecast embeddings: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Backfilled %d forecast embeddings", n)
+		}
+	}()
+
+	// Pick up prompt edits without a restart
+	if interval := systemConfig.EnvConfig.LLMConfig.PromptsWatch; interval > 0 {
+		go reg.PromptService.Watch(backgroundCtx, interval)
+	}
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 	<-shutdown
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
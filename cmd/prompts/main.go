@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"log"
+	"os"
+)
+
+// Checks the prompt templates without starting the app: every template the
+// pipeline uses is executed against sample data.
+func main() {
+	_ = godotenv.Load()
+
+	defaultPath := os.Getenv("LLM_PROMPTS_PATH")
+	if defaultPath == "" {
+		defaultPath = promptgen.DefaultPath
+	}
+
+	path := flag.String("path", defaultPath, "prompt file or directory")
+	render := flag.String("render", "", "print the prompt a template renders from its sample data")
+	flag.Parse()
+
+	promptTemplate, err := promptgen.LoadPromptTemplate(*path)
+	if err != nil {
+		log.Fatalf("Error loading prompt template: %v", err)
+	}
+
+	if err := promptTemplate.Validate(); err != nil {
+		log.Fatalf("Invalid prompt templates in %s:\n%v", *path, err)
+	}
+
+	if *render != "" {
+		name := promptgen.TemplateType(*render)
+		templateType, _ := promptgen.SplitVariant(name)
+		data, _ := promptgen.SampleData(templateType)
+		prompt, err := promptTemplate.CreatePrompt(name, data)
+		if err != nil {
+			log.Fatalf("Error rendering %s: %v", *render, err)
+		}
+		fmt.Println(prompt)
+		return
+	}
+
+	for _, name := range promptTemplate.Names() {
+		version := promptTemplate.Version(name)
+		fmt.Printf("%-34s %-10s %-4d %s\n", name, version.Version, version.Weight, version.Hash)
+	}
+	log.Printf("Prompt templates in %s are valid", *path)
+}
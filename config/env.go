@@ -4,22 +4,84 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type EnvConfig struct {
 	AuthSecret            string `env:"AUTH_SECRET,required"`
 	DatabaseConfig        *DatabaseConfig
 	ExternalServiceConfig *ExternalServiceConfig
+	LLMConfig             *LLMConfig
 	AWSConfig             *AWSConfig
+	CassetteConfig        *CassetteConfig
 }
 
 type ExternalServiceConfig struct {
-	ReplicateModel    string `env:"REPLICATE_MODEL,required"`
-	ReplicateAPIKey   string `env:"REPLICATE_KEY,required"`
 	NewsAPIURL        string `env:"NEWS_API_URL,required"`
 	NewsAPIKey        string `env:"NEWS_API_KEY,required"`
 	PolyMarketBaseURL string `env:"POLYMARKET_BASE_URL,required"`
+	// GDELTURL adds the GDELT DOC 2.0 API as a news source, e.g.
+	// https://api.gdeltproject.org/api/v2/doc/doc. GDELTSourceLang and
+	// GDELTSourceCountry restrict it to e.g. "english" and "US".
+	GDELTURL           string `env:"GDELT_URL"`
+	GDELTSourceLang    string `env:"GDELT_SOURCE_LANG"`
+	GDELTSourceCountry string `env:"GDELT_SOURCE_COUNTRY"`
+	// RSSFeedsFile lists RSS and Atom feeds to take headlines from, per
+	// category. See LoadRSSFeeds.
+	RSSFeedsFile string `env:"RSS_FEEDS_FILE"`
+}
+
+// LLMConfig configures the model backends. Provider is the default backend;
+// RoutesFile may send individual prompt templates to other providers and
+// models. Only the settings of providers in use need to be set.
+type LLMConfig struct {
+	Provider         string `env:"LLM_PROVIDER"`
+	RoutesFile       string `env:"LLM_ROUTES_FILE"`
+	ForecastAttempts int    `env:"LLM_FORECAST_ATTEMPTS"`
+	ReplicateModel   string `env:"REPLICATE_MODEL"`
+	ReplicateAPIKey  string `env:"REPLICATE_KEY"`
+	ReplicateStream  bool   `env:"REPLICATE_STREAM"`
+	OpenAIBaseURL    string `env:"OPENAI_BASE_URL"`
+	OpenAIModel      string `env:"OPENAI_MODEL"`
+	OpenAIAPIKey     string `env:"OPENAI_API_KEY"`
+	LocalServer      string `env:"LOCAL_LLM_SERVER"`
+	LocalURL         string `env:"LOCAL_LLM_URL"`
+	LocalModel       string `env:"LOCAL_LLM_MODEL"`
+	// LocalTemperature is nil when unset, so an explicit 0 is kept
+	LocalTemperature *float64 `env:"LOCAL_LLM_TEMPERATURE"`
+	LocalGrammarFile string   `env:"LOCAL_LLM_GRAMMAR_FILE"`
+	StubFixtureDir   string   `env:"LLM_STUB_FIXTURES"`
+	StubSeed         int64    `env:"LLM_STUB_SEED"`
+	// Pricing is "model=input,output;..." in USD per million tokens.
+	Pricing string `env:"LLM_PRICING"`
+	// Cache is "file", "postgres" or empty to disable response caching.
+	Cache    string        `env:"LLM_CACHE"`
+	CacheDir string        `env:"LLM_CACHE_DIR"`
+	CacheTTL time.Duration `env:"LLM_CACHE_TTL"`
+	// Samples > 1 generates every forecast that many times at
+	// SampleTemperature and keeps the outcomes the samples agree on.
+	// SampleTemperature is nil when unset, so an explicit 0 is kept.
+	Samples           int      `env:"LLM_SAMPLES"`
+	SampleTemperature *float64 `env:"LLM_SAMPLE_TEMPERATURE"`
+	// EmbeddingProvider is "openai", "local", "stub" or empty to match
+	// related forecasts by tags only.
+	EmbeddingProvider string `env:"LLM_EMBEDDING_PROVIDER"`
+	EmbeddingModel    string `env:"LLM_EMBEDDING_MODEL"`
+	// EmbeddingMinSimilarity is the similarity from which a forecast counts
+	// as related under EmbeddingModel. Unset, a default applies.
+	EmbeddingMinSimilarity *float64 `env:"LLM_EMBEDDING_MIN_SIMILARITY"`
+	// PromptsPath is a YAML file or a directory of prompt files. With
+	// PromptsWatch > 0 they are reloaded when they change, checked that often.
+	PromptsPath  string        `env:"LLM_PROMPTS_PATH"`
+	PromptsWatch time.Duration `env:"LLM_PROMPTS_WATCH"`
+}
+
+// CassetteConfig switches the shared HTTP client into record or replay mode.
+type CassetteConfig struct {
+	Mode string `env:"HTTP_CASSETTE_MODE"`
+	Dir  string `env:"HTTP_CASSETTE_DIR"`
 }
 
 type AWSConfig struct {
@@ -32,7 +94,9 @@ func NewEnvConfig() (*EnvConfig, error) {
 	config := &EnvConfig{
 		DatabaseConfig:        &DatabaseConfig{},
 		ExternalServiceConfig: &ExternalServiceConfig{},
+		LLMConfig:             &LLMConfig{},
 		AWSConfig:             &AWSConfig{},
+		CassetteConfig:        &CassetteConfig{},
 	}
 
 	if err := loadEnvVars(config); err != nil {
@@ -70,7 +134,61 @@ func loadEnvVars(config interface{}) error {
 			return fmt.Errorf("required environment variable %s is not set", envKey)
 		}
 
+		if err := setField(field, envKey, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, envKey, value string) error {
+	if field.Kind() != reflect.String && value == "" {
+		return nil
+	}
+
+	// Optional values stay nil when unset
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setField(ptr.Elem(), envKey, value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("environment variable %s must be a duration: %v", envKey, err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
 		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("environment variable %s must be an integer: %v", envKey, err)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("environment variable %s must be a number: %v", envKey, err)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("environment variable %s must be a boolean: %v", envKey, err)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s for environment variable %s", field.Kind(), envKey)
 	}
 
 	return nil
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+)
+
+// LLMRouteConfig picks the provider, model and generation settings for one
+// prompt template. Empty fields fall back to the default route.
+type LLMRouteConfig struct {
+	Provider    string   `yaml:"provider"`
+	Model       string   `yaml:"model"`
+	MaxTokens   int      `yaml:"max_tokens"`
+	Temperature *float64 `yaml:"temperature"`
+	// ContextWindow is the context size of the model in tokens, for models
+	// the prompt budgeting doesn't know.
+	ContextWindow int `yaml:"context_window"`
+}
+
+// LLMRoutesConfig is loaded from the file in LLM_ROUTES_FILE, e.g.
+//
+//	default:
+//	  provider: replicate
+//	  model: meta/meta-llama-3-70b-instruct
+//	routes:
+//	  select_articles:
+//	    provider: openai
+//	    model: gpt-4o-mini
+//	    max_tokens: 100
+//	    temperature: 0
+//	  generate_news_forecast:
+//	    provider: local
+//	    model: my-finetune
+//	    context_window: 16384
+//	ensemble:
+//	  aggregate: median
+//	  members:
+//	    - provider: openai
+//	      model: gpt-4o
+//	    - model: meta/meta-llama-3-70b-instruct
+//	    - name: llama-warm
+//	      model: meta/meta-llama-3-70b-instruct
+//	      temperature: 0.9
+type LLMRoutesConfig struct {
+	Default  LLMRouteConfig            `yaml:"default"`
+	Routes   map[string]LLMRouteConfig `yaml:"routes"`
+	Ensemble LLMEnsembleConfig         `yaml:"ensemble"`
+}
+
+// LLMEnsembleConfig lists the models every forecast is generated with when
+// ensemble mode is on. The first member that answers provides the headline
+// and summary. An empty member list turns ensemble mode off.
+type LLMEnsembleConfig struct {
+	Aggregate string            `yaml:"aggregate"`
+	Members   []LLMMemberConfig `yaml:"members"`
+}
+
+// LLMMemberConfig is the route of one ensemble member. Name labels its
+// outcomes and defaults to the model, numbered when members share a model.
+type LLMMemberConfig struct {
+	Name           string `yaml:"name"`
+	LLMRouteConfig `yaml:",inline"`
+}
+
+func LoadLLMRoutes(path string) (*LLMRoutesConfig, error) {
+	routes := &LLMRoutesConfig{}
+	if path == "" {
+		return routes, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(file, routes); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	return routes, nil
+}
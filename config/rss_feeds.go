@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/util"
+	"gopkg.in/yaml.v2"
+	"os"
+)
+
+// LoadRSSFeeds reads the RSS and Atom feeds to take headlines from, per
+// category, from the file in RSS_FEEDS_FILE, e.g.
+//
+//	Politics:
+//	  - https://feeds.bbci.co.uk/news/politics/rss.xml
+//	  - https://www.theguardian.com/politics/rss
+//	Economy:
+//	  - https://feeds.a.dj.com/rss/RSSMarketsMain.xml
+//	Technology:
+//	  - https://feeds.arstechnica.com/arstechnica/index
+//
+// Categories are matched case-insensitively. An empty path configures no
+// feeds.
+func LoadRSSFeeds(path string) (map[util.Category][]string, error) {
+	feeds := map[util.Category][]string{}
+	if path == "" {
+		return feeds, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string][]string
+	if err := yaml.UnmarshalStrict(file, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	for name, urls := range raw {
+		category, err := util.ParseCategory(name)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", path, err)
+		}
+		feeds[category] = append(feeds[category], urls...)
+	}
+
+	return feeds, nil
+}
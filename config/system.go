@@ -3,15 +3,27 @@ package config
 import (
 	"fmt"
 	"github.com/joho/godotenv"
+	"github.com/qoentz/evedict/internal/cassette"
 	"github.com/qoentz/evedict/internal/promptgen"
+	"github.com/qoentz/evedict/internal/util"
+	"log"
 	"net/http"
 	"os"
 	"time"
 )
 
+const defaultCassetteDir = "testdata/cassettes"
+
+// cassetteIgnoredParams are left out of request matching: API keys must not
+// end up on disk, and Polymarket's start date moves with every run.
+var cassetteIgnoredParams = []string{"apiKey", "start_date_min"}
+
 type SystemConfig struct {
 	EnvConfig      *EnvConfig
 	PromptTemplate *promptgen.PromptTemplate
+	PromptsPath    string
+	LLMRoutes      *LLMRoutesConfig
+	RSSFeeds       map[util.Category][]string
 	HTTPClient     *http.Client
 }
 
@@ -25,18 +37,70 @@ func ConfigureSystem() (*SystemConfig, error) {
 		return nil, fmt.Errorf("error loading .env: %v", err)
 	}
 
-	promptTemplate, err := promptgen.LoadPromptTemplate("internal/promptgen/prompts.yaml")
+	promptsPath := envConfig.LLMConfig.PromptsPath
+	if promptsPath == "" {
+		promptsPath = promptgen.DefaultPath
+	}
+
+	promptTemplate, err := promptgen.LoadPromptTemplate(promptsPath)
 	if err != nil {
 		return nil, fmt.Errorf("error loading prompt template: %v", err)
 	}
 
+	if err := promptTemplate.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid prompt templates in %s: %v", promptsPath, err)
+	}
+
+	llmRoutes, err := LoadLLMRoutes(envConfig.LLMConfig.RoutesFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading LLM routes: %v", err)
+	}
+
+	rssFeeds, err := LoadRSSFeeds(envConfig.ExternalServiceConfig.RSSFeedsFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading RSS feeds: %v", err)
+	}
+
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
+	if err := configureCassette(client, envConfig.CassetteConfig); err != nil {
+		return nil, fmt.Errorf("error configuring HTTP cassette: %v", err)
+	}
+
 	return &SystemConfig{
 		EnvConfig:      envConfig,
 		PromptTemplate: promptTemplate,
+		PromptsPath:    promptsPath,
+		LLMRoutes:      llmRoutes,
+		RSSFeeds:       rssFeeds,
 		HTTPClient:     client,
 	}, nil
 }
+
+func configureCassette(client *http.Client, c *CassetteConfig) error {
+	mode, err := cassette.ParseMode(c.Mode)
+	if err != nil {
+		return err
+	}
+
+	if mode == cassette.Off {
+		return nil
+	}
+
+	dir := c.Dir
+	if dir == "" {
+		dir = defaultCassetteDir
+	}
+
+	transport, err := cassette.NewTransport(mode, dir, client.Transport, cassetteIgnoredParams...)
+	if err != nil {
+		return err
+	}
+
+	client.Transport = transport
+	log.Printf("HTTP cassette in %s mode using %s", mode, dir)
+
+	return nil
+}
@@ -18,4 +18,18 @@ type Forecast struct {
 	Timestamp time.Time     `json:"timestamp"`
 	Market    *Market       `json:"market"`
 	Related   []Forecast    `json:"related"`
+	// Provenance is nil for forecasts saved before it was recorded.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// Embedding is computed at generation time and only stored, never served.
+	Embedding      []float32 `json:"-"`
+	EmbeddingModel string    `json:"-"`
+}
+
+// Provenance records which prompt and model produced a forecast.
+type Provenance struct {
+	TemplateType  string `json:"templateType"`
+	PromptVersion string `json:"promptVersion"`
+	PromptHash    string `json:"promptHash"`
+	PromptVariant string `json:"promptVariant,omitempty"`
+	Model         string `json:"model"`
 }
@@ -6,4 +6,17 @@ type Outcome struct {
 	ID              uuid.UUID `json:"id"`
 	Content         string    `json:"content"`
 	ConfidenceLevel int       `json:"confidenceLevel"`
+	Rationale       string    `json:"rationale"`
+	// SourceIndexes point into the related articles the model was given, and
+	// into Forecast.Sources once the forecast metadata is attached.
+	SourceIndexes []int          `json:"sources"`
+	Contributions []Contribution `json:"contributions,omitempty"`
+}
+
+// Contribution is the outcome one ensemble model proposed before the
+// results were merged.
+type Contribution struct {
+	Model           string `json:"model"`
+	Content         string `json:"content"`
+	ConfidenceLevel int    `json:"confidenceLevel"`
 }
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type PromptInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
+	Weight  int    `json:"weight"`
+}
+
+// PromptStatus describes the prompts in use and the last failed reload, if
+// it happened after they were loaded.
+type PromptStatus struct {
+	Path     string       `json:"path"`
+	LoadedAt time.Time    `json:"loadedAt"`
+	Prompts  []PromptInfo `json:"prompts"`
+	Error    string       `json:"error,omitempty"`
+	FailedAt *time.Time   `json:"failedAt,omitempty"`
+}
+
+// PromptVariantStats compares the variants of a prompt. The rates are nil
+// until there is something to divide by.
+type PromptVariantStats struct {
+	TemplateType     string   `json:"templateType"`
+	Variant          string   `json:"variant"`
+	Weight           int      `json:"weight"`
+	Loaded           bool     `json:"loaded"`
+	Attempts         int      `json:"attempts"`
+	ParseFailures    int      `json:"parseFailures"`
+	Forecasts        int      `json:"forecasts"`
+	Approved         int      `json:"approved"`
+	Rejected         int      `json:"rejected"`
+	ApprovalRate     *float64 `json:"approvalRate,omitempty"`
+	ParseFailureRate *float64 `json:"parseFailureRate,omitempty"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type UsageSummary struct {
+	Key              string    `json:"key"`
+	Calls            int       `json:"calls"`
+	Failures         int       `json:"failures"`
+	PromptTokens     int64     `json:"promptTokens"`
+	CompletionTokens int64     `json:"completionTokens"`
+	AvgLatencyMs     float64   `json:"avgLatencyMs"`
+	MaxLatencyMs     int64     `json:"maxLatencyMs"`
+	EstimatedCost    float64   `json:"estimatedCost"`
+	StartedAt        time.Time `json:"startedAt"`
+}
+
+type UsageReport struct {
+	Days       int            `json:"days"`
+	ByDay      []UsageSummary `json:"byDay"`
+	ByTemplate []UsageSummary `json:"byTemplate"`
+	ByRun      []UsageSummary `json:"byRun"`
+}
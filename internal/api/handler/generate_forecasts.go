@@ -3,7 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"github.com/qoentz/evedict/internal/service"
 	"net/http"
 )
@@ -15,13 +15,13 @@ func GenerateForecasts(s *service.ForecastService) http.HandlerFunc {
 			category = "general"
 		}
 
-		newsCategory, err := newsapi.ValidateCategory(category)
+		newsCategory, err := eventfeed.ValidateCategory(category)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Invalid category: %v", err), http.StatusBadRequest)
 			return
 		}
 
-		forecasts, err := s.GenerateForecasts(newsCategory)
+		forecasts, err := s.GenerateForecasts(r.Context(), newsCategory)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Couldn't generate forecasts: %v", err), http.StatusInternalServerError)
 			return
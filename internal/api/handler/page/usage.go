@@ -0,0 +1,37 @@
+package page
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/service"
+	"github.com/qoentz/evedict/internal/view"
+	"net/http"
+	"strconv"
+)
+
+func Usage(s *service.UsageService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		days := 30
+		if d := r.URL.Query().Get("days"); d != "" {
+			var err error
+			days, err = strconv.Atoi(d)
+			if err != nil || days < 1 {
+				http.Error(w, "invalid days", http.StatusBadRequest)
+				return
+			}
+		}
+
+		report, err := s.GetUsageReport(days, 20)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Couldn't get usage: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		err = view.UsagePage(report).Render(r.Context(), w)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -2,15 +2,22 @@ package page
 
 import (
 	"fmt"
+	"github.com/qoentz/evedict/internal/service"
 	"github.com/qoentz/evedict/internal/view"
 	"net/http"
 )
 
-func WorkSpace() http.HandlerFunc {
+func WorkSpace(s *service.PromptService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-		err := view.WorkSpacePage().Render(r.Context(), w)
+		variants, err := s.GetVariantStats()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Couldn't get prompt variant stats: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		err = view.WorkSpacePage(s.Status(), variants).Render(r.Context(), w)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
 			return
@@ -9,7 +9,7 @@ import (
 
 func GeneratePolyForecasts(s *service.ForecastService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		forecasts, err := s.GeneratePolyForecasts()
+		forecasts, err := s.GeneratePolyForecasts(r.Context())
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Couldn't generate forecasts: %v", err), http.StatusInternalServerError)
 			return
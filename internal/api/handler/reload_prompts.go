@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/service"
+	"github.com/qoentz/evedict/internal/view"
+	"net/http"
+)
+
+// ReloadPrompts reloads the prompt files and renders the prompt status. A
+// failed reload is shown in the status rather than as an error response, so
+// the workspace swaps it in.
+func ReloadPrompts(s *service.PromptService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		_ = s.Reload()
+
+		variants, err := s.GetVariantStats()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Couldn't get prompt variant stats: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		err = view.PromptStatusPanel(s.Status(), variants).Render(r.Context(), w)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -48,9 +48,12 @@ func InitRouter(reg *registry.Registry) *mux.Router {
 
 	// Admin endpoints
 	vault := protected.PathPrefix("/vault").Subrouter()
-	vault.HandleFunc("/workspace", page.WorkSpace()).Methods("GET")
+	vault.HandleFunc("/workspace", page.WorkSpace(reg.PromptService)).Methods("GET")
 	vault.HandleFunc("/workspace/pending", fragment.GetPendingForecastsFragment(reg.ForecastService)).Methods("GET")
 	vault.HandleFunc("/forecasts/{forecastId}", handler.ApproveForecast(reg.ForecastService)).Methods("PATCH")
+	vault.HandleFunc("/forecasts/{forecastId}/reject", handler.RejectForecast(reg.ForecastService)).Methods("PATCH")
+	vault.HandleFunc("/usage", page.Usage(reg.UsageService)).Methods("GET")
+	vault.HandleFunc("/prompts/reload", handler.ReloadPrompts(reg.PromptService)).Methods("POST")
 
 	invoke := vault.PathPrefix("/invoke").Subrouter()
 	invoke.Handle("/forecast/default", handler.GenerateForecasts(reg.ForecastService)).Methods("POST")
@@ -0,0 +1,243 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+type Mode string
+
+const (
+	Off    Mode = ""
+	Record Mode = "record"
+	Replay Mode = "replay"
+)
+
+const redacted = "REDACTED"
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sensitiveHeaders are never written to a cassette.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+func ParseMode(mode string) (Mode, error) {
+	switch Mode(mode) {
+	case Off, Record, Replay:
+		return Mode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid cassette mode: %s", mode)
+	}
+}
+
+// Transport is an http.RoundTripper that records every exchange into
+// cassette files, or serves previously recorded exchanges without touching
+// the network.
+//
+// Requests are matched on method, URL and body. Query parameters listed in
+// IgnoreParams (API keys, moving date windows) are left out of the match and
+// redacted on disk. Identical requests, such as Replicate status polls, are
+// numbered in the order they are made and replayed in that order; once the
+// recording runs out the last response is repeated.
+type Transport struct {
+	Mode         Mode
+	Dir          string
+	Next         http.RoundTripper
+	IgnoreParams []string
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+type Interaction struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recordedAt"`
+}
+
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+}
+
+var _ http.RoundTripper = &Transport{}
+
+func NewTransport(mode Mode, dir string, next http.RoundTripper, ignoreParams ...string) (*Transport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if mode == Record {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("error creating cassette directory: %v", err)
+		}
+	}
+
+	return &Transport{
+		Mode:         mode,
+		Dir:          dir,
+		Next:         next,
+		IgnoreParams: ignoreParams,
+		seen:         map[string]int{},
+	}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Mode == Off {
+		return t.Next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	matchURL := t.normalizeURL(req.URL)
+	key := t.key(req.Method, req.URL.Host, matchURL, body)
+
+	t.mu.Lock()
+	seq := t.seen[key]
+	t.seen[key]++
+	t.mu.Unlock()
+
+	if t.Mode == Replay {
+		return t.replay(req, key, seq)
+	}
+
+	return t.record(req, key, seq, matchURL, body)
+}
+
+func (t *Transport) record(req *http.Request, key string, seq int, matchURL string, body []byte) (*http.Response, error) {
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     matchURL,
+			Headers: redactHeaders(req.Header),
+			Body:    string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    redactHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+
+	b, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(t.path(key, seq), b, 0o644); err != nil {
+		return nil, fmt.Errorf("error writing cassette: %v", err)
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, key string, seq int) (*http.Response, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	for ; seq >= 0; seq-- {
+		b, err = os.ReadFile(t.path(key, seq))
+		if !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no recorded response for %s %s", req.Method, t.normalizeURL(req.URL))
+		}
+		return nil, fmt.Errorf("error reading cassette: %v", err)
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(b, &interaction); err != nil {
+		return nil, fmt.Errorf("error parsing cassette: %v", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Headers,
+		Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) normalizeURL(u *url.URL) string {
+	clean := *u
+	query := clean.Query()
+	for _, param := range t.IgnoreParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+func (t *Transport) key(method, host, matchURL string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + matchURL + "\n"))
+	h.Write(body)
+	return fmt.Sprintf("%s_%s_%s", method, unsafePathChars.ReplaceAllString(host, "_"), hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+func (t *Transport) path(key string, seq int) string {
+	return filepath.Join(t.Dir, fmt.Sprintf("%s_%03d.json", key, seq))
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	clean := headers.Clone()
+	for _, h := range sensitiveHeaders {
+		if clean.Get(h) != "" {
+			clean.Set(h, redacted)
+		}
+	}
+	return clean
+}
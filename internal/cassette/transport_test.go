@@ -0,0 +1,205 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// statusServer answers every request with the number of requests it has
+// served so far.
+func statusServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Call", fmt.Sprint(n))
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "call %d for %s", n, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func newClient(t *testing.T, mode Mode, dir string, ignoreParams ...string) *http.Client {
+	t.Helper()
+
+	transport, err := NewTransport(mode, dir, nil, ignoreParams...)
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	return &http.Client{Transport: transport}
+}
+
+func TestRecordReplay(t *testing.T) {
+	server, calls := statusServer(t)
+	dir := filepath.Join(t.TempDir(), "cassettes")
+
+	recorder := newClient(t, Record, dir)
+	resp, err := recorder.Post(server.URL+"/predictions", "application/json", strings.NewReader(`{"prompt": "a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(recorded) != `call 1 for {"prompt": "a"}` {
+		t.Fatalf("recorded body = %q", recorded)
+	}
+
+	player := newClient(t, Replay, dir)
+	resp, err = player.Post(server.URL+"/predictions", "application/json", strings.NewReader(`{"prompt": "a"}`))
+	if err != nil {
+		t.Fatalf("replay error: %v", err)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(replayed) != string(recorded) {
+		t.Errorf("replayed body = %q, want %q", replayed, recorded)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Call") != "1" {
+		t.Errorf("replayed status %d with X-Call %q", resp.StatusCode, resp.Header.Get("X-Call"))
+	}
+	if atomic.LoadInt32(calls) != 1 {
+		t.Errorf("server saw %d calls, want replay to stay off the network", atomic.LoadInt32(calls))
+	}
+
+	// A different body is a different request
+	if _, err := player.Post(server.URL+"/predictions", "application/json", strings.NewReader(`{"prompt": "b"}`)); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("replaying an unrecorded request: %v", err)
+	}
+}
+
+func TestReplaySequence(t *testing.T) {
+	server, calls := statusServer(t)
+	dir := t.TempDir()
+
+	recorder := newClient(t, Record, dir)
+	for i := 0; i < 2; i++ {
+		get(t, recorder, server.URL+"/status")
+	}
+
+	player := newClient(t, Replay, dir)
+	want := []string{"call 1 for ", "call 2 for ", "call 2 for ", "call 2 for "}
+	for i, w := range want {
+		if got := get(t, player, server.URL+"/status"); got != w {
+			t.Errorf("replay %d = %q, want %q", i+1, got, w)
+		}
+	}
+	if atomic.LoadInt32(calls) != 2 {
+		t.Errorf("server saw %d calls, want 2", atomic.LoadInt32(calls))
+	}
+
+	// Every player numbers identical requests from the start
+	if got := get(t, newClient(t, Replay, dir), server.URL+"/status"); got != "call 1 for " {
+		t.Errorf("new player replayed %q, want the first call", got)
+	}
+}
+
+func TestIgnoreParams(t *testing.T) {
+	server, _ := statusServer(t)
+	dir := t.TempDir()
+
+	recorder := newClient(t, Record, dir, "apiKey")
+	get(t, recorder, server.URL+"/everything?q=fed&apiKey=first-key")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(files))
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "first-key") {
+		t.Errorf("the API key was written to disk:\n%s", b)
+	}
+	if !strings.Contains(string(b), "apiKey=REDACTED") {
+		t.Errorf("the redacted parameter is missing from the cassette:\n%s", b)
+	}
+
+	// Another key matches the same recording, other parameters don't
+	player := newClient(t, Replay, dir, "apiKey")
+	if got := get(t, player, server.URL+"/everything?q=fed&apiKey=second-key"); got != "call 1 for " {
+		t.Errorf("replay with another key = %q", got)
+	}
+	if _, err := player.Get(server.URL + "/everything?q=ecb&apiKey=first-key"); err == nil {
+		t.Error("replayed a request with another query")
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	server, _ := statusServer(t)
+	dir := t.TempDir()
+
+	recorder := newClient(t, Record, dir)
+	req, err := http.NewRequest("GET", server.URL+"/models", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Api-Key", "secret-key")
+	req.Header.Set("Accept", "application/json")
+	resp, err := recorder.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// The caller still sees the live headers
+	if resp.Header.Get("Set-Cookie") != "session=secret" {
+		t.Errorf("recording changed the live response headers: %v", resp.Header)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(files))
+	}
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-token", "secret-key", "session=secret"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("%q was written to disk:\n%s", secret, b)
+		}
+	}
+	if !strings.Contains(string(b), "application/json") {
+		t.Errorf("a non-sensitive header was dropped:\n%s", b)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range []string{"", "record", "replay"} {
+		if _, err := ParseMode(mode); err != nil {
+			t.Errorf("ParseMode(%q) error: %v", mode, err)
+		}
+	}
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Error("ParseMode(rewind) returned no error")
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE llm_cache (
+                           template_type VARCHAR(255) NOT NULL,
+                           model VARCHAR(255) NOT NULL,
+                           prompt_hash CHAR(64) NOT NULL,
+                           output TEXT NOT NULL,
+                           created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                           PRIMARY KEY (template_type, model, prompt_hash)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE llm_call (
+                          id UUID PRIMARY KEY,
+                          run_id UUID,
+                          template_type VARCHAR(255) NOT NULL,
+                          model VARCHAR(255) NOT NULL,
+                          prompt_chars INT NOT NULL,
+                          output_chars INT NOT NULL,
+                          prompt_tokens INT,
+                          completion_tokens INT,
+                          latency_ms BIGINT NOT NULL,
+                          status VARCHAR(32) NOT NULL,
+                          error TEXT,
+                          estimated_cost NUMERIC(12, 6) NOT NULL DEFAULT 0,
+                          created_at TIMESTAMPTZ NOT NULL,
+                          CHECK (status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX idx_llm_call_created_at ON llm_call(created_at);
+CREATE INDEX idx_llm_call_run_id ON llm_call(run_id);
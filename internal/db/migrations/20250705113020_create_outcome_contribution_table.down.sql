@@ -0,0 +1 @@
+DROP TABLE IF EXISTS outcome_contribution;
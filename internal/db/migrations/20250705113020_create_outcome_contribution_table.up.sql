@@ -0,0 +1,9 @@
+CREATE TABLE outcome_contribution (
+                                      id UUID PRIMARY KEY,
+                                      outcome_id UUID NOT NULL REFERENCES outcome(id) ON DELETE CASCADE,
+                                      model VARCHAR(255) NOT NULL,
+                                      content TEXT NOT NULL,
+                                      confidence_level INT CHECK (confidence_level >= 0 AND confidence_level <= 100) NOT NULL
+);
+
+CREATE INDEX idx_outcome_contribution_outcome_id ON outcome_contribution(outcome_id);
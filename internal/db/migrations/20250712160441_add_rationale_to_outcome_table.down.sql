@@ -0,0 +1,2 @@
+ALTER TABLE outcome
+DROP COLUMN rationale;
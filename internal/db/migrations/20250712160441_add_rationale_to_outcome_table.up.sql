@@ -0,0 +1,2 @@
+ALTER TABLE outcome
+ADD COLUMN rationale TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE outcome_source (
+                                outcome_id UUID NOT NULL REFERENCES outcome(id) ON DELETE CASCADE,
+                                source_id UUID NOT NULL REFERENCES source(id) ON DELETE CASCADE,
+                                PRIMARY KEY (outcome_id, source_id)
+);
+
+CREATE INDEX idx_outcome_source_source_id ON outcome_source(source_id);
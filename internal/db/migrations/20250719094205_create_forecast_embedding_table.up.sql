@@ -0,0 +1,9 @@
+CREATE TABLE forecast_embedding (
+                                    forecast_id UUID NOT NULL REFERENCES forecast(id) ON DELETE CASCADE,
+                                    model VARCHAR(255) NOT NULL,
+                                    embedding REAL[] NOT NULL,
+                                    created_at TIMESTAMPTZ NOT NULL,
+                                    PRIMARY KEY (forecast_id, model)
+);
+
+CREATE INDEX idx_forecast_embedding_model ON forecast_embedding(model);
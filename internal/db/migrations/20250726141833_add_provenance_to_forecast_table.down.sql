@@ -0,0 +1,5 @@
+ALTER TABLE forecast
+DROP COLUMN template_type,
+DROP COLUMN prompt_version,
+DROP COLUMN prompt_hash,
+DROP COLUMN model;
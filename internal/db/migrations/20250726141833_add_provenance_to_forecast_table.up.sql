@@ -0,0 +1,5 @@
+ALTER TABLE forecast
+ADD COLUMN template_type VARCHAR(255),
+ADD COLUMN prompt_version VARCHAR(255),
+ADD COLUMN prompt_hash VARCHAR(64),
+ADD COLUMN model TEXT;
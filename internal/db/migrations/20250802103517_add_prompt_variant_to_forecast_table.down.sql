@@ -0,0 +1,2 @@
+ALTER TABLE forecast
+DROP COLUMN prompt_variant;
@@ -0,0 +1,2 @@
+ALTER TABLE forecast
+ADD COLUMN prompt_variant VARCHAR(255);
@@ -0,0 +1,2 @@
+ALTER TABLE forecast
+ADD COLUMN is_rejected BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,14 @@
+CREATE TABLE forecast_attempt (
+                                  id UUID PRIMARY KEY,
+                                  run_id UUID,
+                                  template_type VARCHAR(255) NOT NULL,
+                                  prompt_variant VARCHAR(255) NOT NULL,
+                                  prompt_hash VARCHAR(64) NOT NULL,
+                                  model TEXT NOT NULL,
+                                  attempt INT NOT NULL,
+                                  parsed BOOLEAN NOT NULL,
+                                  error TEXT,
+                                  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_forecast_attempt_variant ON forecast_attempt(template_type, prompt_variant);
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ForecastAttempt is one forecast answer of the model and whether it passed
+// validation.
+type ForecastAttempt struct {
+	ID            uuid.UUID  `db:"id"`
+	RunID         *uuid.UUID `db:"run_id"`
+	TemplateType  string     `db:"template_type"`
+	PromptVariant string     `db:"prompt_variant"`
+	PromptHash    string     `db:"prompt_hash"`
+	Model         string     `db:"model"`
+	Attempt       int        `db:"attempt"`
+	Parsed        bool       `db:"parsed"`
+	Error         *string    `db:"error"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// PromptVariantStats counts the attempts and reviews of the forecasts a prompt
+// variant produced.
+type PromptVariantStats struct {
+	TemplateType  string `db:"template_type"`
+	PromptVariant string `db:"prompt_variant"`
+	Attempts      int    `db:"attempts"`
+	ParseFailures int    `db:"parse_failures"`
+	Forecasts     int    `db:"forecasts"`
+	Approved      int    `db:"approved"`
+	Rejected      int    `db:"rejected"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
+)
+
+type ForecastEmbedding struct {
+	ForecastID uuid.UUID       `db:"forecast_id"`
+	Model      string          `db:"model"`
+	Embedding  pq.Float32Array `db:"embedding"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
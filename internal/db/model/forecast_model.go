@@ -13,8 +13,15 @@ type Forecast struct {
 	ImageURL  string        `db:"image_url"`
 	Category  util.Category `db:"category"`
 	Timestamp time.Time     `db:"timestamp"`
-	Outcomes  []Outcome     `db:"-"`
-	Tags      []Tag         `db:"-"`
-	Sources   []Source      `db:"-"`
-	Market    *Market       `db:"-"`
+	// Provenance, NULL for forecasts saved before it was recorded
+	TemplateType  *string            `db:"template_type"`
+	PromptVersion *string            `db:"prompt_version"`
+	PromptHash    *string            `db:"prompt_hash"`
+	PromptVariant *string            `db:"prompt_variant"`
+	Model         *string            `db:"model"`
+	Outcomes      []Outcome          `db:"-"`
+	Tags          []Tag              `db:"-"`
+	Sources       []Source           `db:"-"`
+	Market        *Market            `db:"-"`
+	Embedding     *ForecastEmbedding `db:"-"`
 }
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type LLMCacheEntry struct {
+	TemplateType string    `db:"template_type" json:"templateType"`
+	Model        string    `db:"model" json:"model"`
+	PromptHash   string    `db:"prompt_hash" json:"promptHash"`
+	Output       string    `db:"output" json:"output"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+}
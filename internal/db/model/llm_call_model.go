@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type LLMCall struct {
+	ID               uuid.UUID  `db:"id"`
+	RunID            *uuid.UUID `db:"run_id"`
+	TemplateType     string     `db:"template_type"`
+	Model            string     `db:"model"`
+	PromptChars      int        `db:"prompt_chars"`
+	OutputChars      int        `db:"output_chars"`
+	PromptTokens     *int       `db:"prompt_tokens"`
+	CompletionTokens *int       `db:"completion_tokens"`
+	LatencyMs        int64      `db:"latency_ms"`
+	Status           string     `db:"status"`
+	Error            *string    `db:"error"`
+	EstimatedCost    float64    `db:"estimated_cost"`
+	CreatedAt        time.Time  `db:"created_at"`
+}
+
+// LLMUsageSummary aggregates llm_call rows under a grouping key such as a
+// day, a template type or a generation run.
+type LLMUsageSummary struct {
+	Key              string    `db:"key"`
+	Calls            int       `db:"calls"`
+	Failures         int       `db:"failures"`
+	PromptTokens     int64     `db:"prompt_tokens"`
+	CompletionTokens int64     `db:"completion_tokens"`
+	AvgLatencyMs     float64   `db:"avg_latency_ms"`
+	MaxLatencyMs     int64     `db:"max_latency_ms"`
+	EstimatedCost    float64   `db:"estimated_cost"`
+	StartedAt        time.Time `db:"started_at"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/google/uuid"
+
+type OutcomeContribution struct {
+	ID              uuid.UUID `db:"id"`
+	OutcomeID       uuid.UUID `db:"outcome_id"`
+	Model           string    `db:"model"`
+	Content         string    `db:"content"`
+	ConfidenceLevel int       `db:"confidence_level"`
+}
@@ -0,0 +1,8 @@
+package model
+
+import "github.com/google/uuid"
+
+type OutcomeSource struct {
+	OutcomeID uuid.UUID `db:"outcome_id"`
+	SourceID  uuid.UUID `db:"source_id"`
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/qoentz/evedict/internal/db/model"
+)
+
+type ForecastAttemptRepository struct {
+	DB *sqlx.DB
+}
+
+func NewForecastAttemptRepository(db *sqlx.DB) *ForecastAttemptRepository {
+	return &ForecastAttemptRepository{
+		DB: db,
+	}
+}
+
+func (r *ForecastAttemptRepository) SaveForecastAttempt(attempt *model.ForecastAttempt) error {
+	query := `
+        INSERT INTO forecast_attempt (id, run_id, template_type, prompt_variant, prompt_hash, model, attempt, parsed, error, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+	_, err := r.DB.Exec(query,
+		attempt.ID,
+		attempt.RunID,
+		attempt.TemplateType,
+		attempt.PromptVariant,
+		attempt.PromptHash,
+		attempt.Model,
+		attempt.Attempt,
+		attempt.Parsed,
+		attempt.Error,
+		attempt.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert forecast attempt: %v", err)
+	}
+
+	return nil
+}
+
+// GetPromptVariantStats combines the attempts of each prompt variant with the
+// review state of the forecasts it produced. Forecasts saved before variants
+// were recorded are left out.
+func (r *ForecastAttemptRepository) GetPromptVariantStats() ([]model.PromptVariantStats, error) {
+	var stats []model.PromptVariantStats
+	query := `
+        WITH attempts AS (
+            SELECT template_type, prompt_variant,
+                   COUNT(*) AS attempts,
+                   COUNT(*) FILTER (WHERE NOT parsed) AS parse_failures
+            FROM forecast_attempt
+            GROUP BY template_type, prompt_variant
+        ), forecasts AS (
+            SELECT template_type, prompt_variant,
+                   COUNT(*) AS forecasts,
+                   COUNT(*) FILTER (WHERE is_approved) AS approved,
+                   COUNT(*) FILTER (WHERE is_rejected) AS rejected
+            FROM forecast
+            WHERE template_type IS NOT NULL AND prompt_variant IS NOT NULL
+            GROUP BY template_type, prompt_variant
+        )
+        SELECT COALESCE(a.template_type, f.template_type) AS template_type,
+               COALESCE(a.prompt_variant, f.prompt_variant) AS prompt_variant,
+               COALESCE(a.attempts, 0) AS attempts,
+               COALESCE(a.parse_failures, 0) AS parse_failures,
+               COALESCE(f.forecasts, 0) AS forecasts,
+               COALESCE(f.approved, 0) AS approved,
+               COALESCE(f.rejected, 0) AS rejected
+        FROM attempts a
+        FULL JOIN forecasts f
+            ON a.template_type = f.template_type AND a.prompt_variant = f.prompt_variant
+        ORDER BY template_type, prompt_variant
+    `
+	if err := r.DB.Select(&stats, query); err != nil {
+		return nil, fmt.Errorf("failed to fetch prompt variant stats: %v", err)
+	}
+
+	return stats, nil
+}
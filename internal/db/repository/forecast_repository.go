@@ -27,29 +27,32 @@ func (r *ForecastRepository) GetForecasts(limit int, offset int, category *util.
 
 	if category != nil {
 		query := `
-            SELECT id, headline, summary, image_url, timestamp 
+            SELECT id, headline, summary, image_url, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model
             FROM forecast
             WHERE category = $1
             AND is_approved = $4
+            AND is_rejected = false
             ORDER BY timestamp DESC
             LIMIT $2 OFFSET $3
         `
 		err = r.DB.Select(&forecasts, query, *category, limit, offset, isApproved)
 	} else if mainFeed {
 		query := `
-            SELECT id, headline, summary, image_url, timestamp 
+            SELECT id, headline, summary, image_url, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model
             FROM forecast
             WHERE category IN ('Politics', 'Economy', 'Technology')
             AND is_approved = $3
+            AND is_rejected = false
             ORDER BY timestamp DESC
             LIMIT $1 OFFSET $2
         `
 		err = r.DB.Select(&forecasts, query, limit, offset, isApproved)
 	} else {
 		query := `
-            SELECT id, headline, summary, image_url, timestamp 
+            SELECT id, headline, summary, image_url, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model
             FROM forecast
             WHERE is_approved = $3
+            AND is_rejected = false
             ORDER BY timestamp DESC
             LIMIT $1 OFFSET $2
         `
@@ -66,7 +69,7 @@ func (r *ForecastRepository) GetForecasts(limit int, offset int, category *util.
 func (r *ForecastRepository) GetForecast(forecastID uuid.UUID) (*model.Forecast, error) {
 	var f model.Forecast
 	forecastQuery := `
-        SELECT id, headline, summary, image_url, category, timestamp
+        SELECT id, headline, summary, image_url, category, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model
         FROM forecast
         WHERE id = $1
     `
@@ -163,22 +166,28 @@ func (r *ForecastRepository) SaveForecast(forecast *model.Forecast) error {
 	}
 
 	// Insert the main Forecast record with a specified UUID
-	query := `INSERT INTO forecast (id, headline, summary, image_url, timestamp) 
-              VALUES ($1, $2, $3, $4, $5)`
-	_, err = tx.Exec(query, forecast.ID, forecast.Headline, forecast.Summary, forecast.ImageURL, forecast.Timestamp)
+	query := `INSERT INTO forecast (id, headline, summary, image_url, category, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = tx.Exec(query, forecast.ID, forecast.Headline, forecast.Summary, forecast.ImageURL, forecast.Category, forecast.Timestamp,
+		forecast.TemplateType, forecast.PromptVersion, forecast.PromptHash, forecast.PromptVariant, forecast.Model)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to insert forecast: %v", err)
 	}
 
 	// Insert associated Outcomes with specified UUIDs
-	outcomeQuery := `INSERT INTO outcome (id, forecast_id, content, confidence_level) VALUES ($1, $2, $3, $4)`
+	outcomeQuery := `INSERT INTO outcome (id, forecast_id, content, confidence_level, rationale) VALUES ($1, $2, $3, $4, $5)`
 	for _, outcome := range forecast.Outcomes {
-		_, err = tx.Exec(outcomeQuery, outcome.ID, forecast.ID, outcome.Content, outcome.ConfidenceLevel)
+		_, err = tx.Exec(outcomeQuery, outcome.ID, forecast.ID, outcome.Content, outcome.ConfidenceLevel, outcome.Rationale)
 		if err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to insert outcome: %v", err)
 		}
+
+		if err = insertContributions(tx, outcome.Contributions); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
 	// Insert associated Sources with specified UUIDs
@@ -191,6 +200,19 @@ func (r *ForecastRepository) SaveForecast(forecast *model.Forecast) error {
 		}
 	}
 
+	// Outcome citations reference the sources, so they go in last
+	if err = insertOutcomeSources(tx, forecast.Outcomes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if forecast.Embedding != nil {
+		if err = insertEmbedding(tx, forecast.ID, forecast.Embedding); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	// Commit the transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %v", err)
@@ -208,13 +230,13 @@ func (r *ForecastRepository) SavePolyForecasts(forecasts []model.Forecast) error
 
 	// Forecast INSERT query
 	forecastQuery := `
-        INSERT INTO forecast (id, headline, summary, image_url, category, timestamp)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO forecast (id, headline, summary, image_url, category, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
     `
 
 	outcomeQuery := `
-        INSERT INTO outcome (id, forecast_id, content, confidence_level)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO outcome (id, forecast_id, content, confidence_level, rationale)
+        VALUES ($1, $2, $3, $4, $5)
     `
 
 	sourceQuery := `
@@ -265,6 +287,11 @@ func (r *ForecastRepository) SavePolyForecasts(forecasts []model.Forecast) error
 			forecast.ImageURL,
 			forecast.Category,
 			forecast.Timestamp,
+			forecast.TemplateType,
+			forecast.PromptVersion,
+			forecast.PromptHash,
+			forecast.PromptVariant,
+			forecast.Model,
 		)
 		if err != nil {
 			tx.Rollback()
@@ -278,11 +305,17 @@ func (r *ForecastRepository) SavePolyForecasts(forecasts []model.Forecast) error
 				forecast.ID,
 				outcome.Content,
 				outcome.ConfidenceLevel,
+				outcome.Rationale,
 			)
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("failed to insert outcome: %v", err)
 			}
+
+			if err = insertContributions(tx, outcome.Contributions); err != nil {
+				tx.Rollback()
+				return err
+			}
 		}
 
 		// === TAGS ===
@@ -319,6 +352,20 @@ func (r *ForecastRepository) SavePolyForecasts(forecasts []model.Forecast) error
 			}
 		}
 
+		// === OUTCOME CITATIONS (after sources) ===
+		if err = insertOutcomeSources(tx, forecast.Outcomes); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// === EMBEDDING ===
+		if forecast.Embedding != nil {
+			if err = insertEmbedding(tx, forecast.ID, forecast.Embedding); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
 		// === MARKET (1:1) ===
 		if forecast.Market != nil {
 			// Insert into market table
@@ -361,14 +408,14 @@ func (r *ForecastRepository) SaveForecasts(forecasts []model.Forecast) error {
 
 	// 1) Prepare the forecast INSERT query (note the "category" field is included now)
 	forecastQuery := `
-        INSERT INTO forecast (id, headline, summary, image_url, category, timestamp)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO forecast (id, headline, summary, image_url, category, timestamp, template_type, prompt_version, prompt_hash, prompt_variant, model)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
     `
 
 	// 2) Prepare the others (same as before)
 	outcomeQuery := `
-        INSERT INTO outcome (id, forecast_id, content, confidence_level)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO outcome (id, forecast_id, content, confidence_level, rationale)
+        VALUES ($1, $2, $3, $4, $5)
     `
 	sourceQuery := `
         INSERT INTO source (id, forecast_id, name, title, url, image_url)
@@ -400,6 +447,11 @@ func (r *ForecastRepository) SaveForecasts(forecasts []model.Forecast) error {
 			forecast.ImageURL,
 			forecast.Category, // <--- category now included here
 			forecast.Timestamp,
+			forecast.TemplateType,
+			forecast.PromptVersion,
+			forecast.PromptHash,
+			forecast.PromptVariant,
+			forecast.Model,
 		)
 		if err != nil {
 			tx.Rollback()
@@ -413,11 +465,17 @@ func (r *ForecastRepository) SaveForecasts(forecasts []model.Forecast) error {
 				forecast.ID,
 				outcome.Content,
 				outcome.ConfidenceLevel,
+				outcome.Rationale,
 			)
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("failed to insert outcome: %v", err)
 			}
+
+			if err = insertContributions(tx, outcome.Contributions); err != nil {
+				tx.Rollback()
+				return err
+			}
 		}
 
 		// === TAGS ===
@@ -453,6 +511,20 @@ func (r *ForecastRepository) SaveForecasts(forecasts []model.Forecast) error {
 				return fmt.Errorf("failed to insert source: %v", err)
 			}
 		}
+
+		// === OUTCOME CITATIONS (after sources) ===
+		if err = insertOutcomeSources(tx, forecast.Outcomes); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// === EMBEDDING ===
+		if forecast.Embedding != nil {
+			if err = insertEmbedding(tx, forecast.ID, forecast.Embedding); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
 	}
 
 	// Commit the transaction
@@ -463,10 +535,110 @@ func (r *ForecastRepository) SaveForecasts(forecasts []model.Forecast) error {
 	return nil
 }
 
+// GetRelatedForecastsByIDs returns the forecasts with the given IDs in the
+// order of ids.
+func (r *ForecastRepository) GetRelatedForecastsByIDs(ids []uuid.UUID) ([]model.RelatedForecast, error) {
+	var forecasts []model.RelatedForecast
+	query := `
+        SELECT id, headline, summary, image_url, timestamp, FALSE AS matched_by_tag
+        FROM forecast
+        WHERE id = ANY($1)
+    `
+	if err := r.DB.Select(&forecasts, query, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to fetch related forecasts: %v", err)
+	}
+
+	byID := make(map[uuid.UUID]model.RelatedForecast, len(forecasts))
+	for _, f := range forecasts {
+		byID[f.ID] = f
+	}
+
+	ordered := make([]model.RelatedForecast, 0, len(forecasts))
+	for _, id := range ids {
+		if f, ok := byID[id]; ok {
+			ordered = append(ordered, f)
+		}
+	}
+
+	return ordered, nil
+}
+
+func (r *ForecastRepository) GetForecastEmbedding(forecastID uuid.UUID, modelName string) (*model.ForecastEmbedding, error) {
+	var e model.ForecastEmbedding
+	query := `
+        SELECT forecast_id, model, embedding, created_at
+        FROM forecast_embedding
+        WHERE forecast_id = $1 AND model = $2
+    `
+	err := r.DB.Get(&e, query, forecastID, modelName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch forecast embedding: %v", err)
+	}
+	return &e, nil
+}
+
+// GetApprovedEmbeddings returns the embeddings of the limit newest approved
+// forecasts except excludeID, for ranking by similarity in memory.
+func (r *ForecastRepository) GetApprovedEmbeddings(modelName string, excludeID uuid.UUID, limit int) ([]model.ForecastEmbedding, error) {
+	var embeddings []model.ForecastEmbedding
+	query := `
+        SELECT e.forecast_id, e.model, e.embedding, e.created_at
+        FROM forecast_embedding e
+        JOIN forecast f ON f.id = e.forecast_id
+        WHERE e.model = $1
+          AND e.forecast_id <> $2
+          AND f.is_approved = true
+        ORDER BY f.timestamp DESC
+        LIMIT $3
+    `
+	if err := r.DB.Select(&embeddings, query, modelName, excludeID, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch forecast embeddings: %v", err)
+	}
+	return embeddings, nil
+}
+
+// GetForecastIDsWithoutEmbedding lists forecasts that have no embedding from
+// modelName yet, newest first.
+func (r *ForecastRepository) GetForecastIDsWithoutEmbedding(modelName string, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+        SELECT f.id
+        FROM forecast f
+        WHERE NOT EXISTS (
+            SELECT 1 FROM forecast_embedding e
+            WHERE e.forecast_id = f.id AND e.model = $1
+        )
+        ORDER BY f.timestamp DESC
+        LIMIT $2
+    `
+	if err := r.DB.Select(&ids, query, modelName, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch forecasts without embedding: %v", err)
+	}
+	return ids, nil
+}
+
+func (r *ForecastRepository) SaveForecastEmbedding(forecastID uuid.UUID, embedding *model.ForecastEmbedding) error {
+	return insertEmbedding(r.DB, forecastID, embedding)
+}
+
 func (r *ForecastRepository) MarkForecastApproved(forecastID uuid.UUID) error {
 	_, err := r.DB.Exec(`
 		UPDATE forecast
-		SET is_approved = true
+		SET is_approved = true, is_rejected = false
+		WHERE id = $1
+	`, forecastID)
+	return err
+}
+
+// MarkForecastRejected keeps the forecast for the prompt statistics but takes
+// it out of the pending queue.
+func (r *ForecastRepository) MarkForecastRejected(forecastID uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE forecast
+		SET is_rejected = true, is_approved = false
 		WHERE id = $1
 	`, forecastID)
 	return err
@@ -474,8 +646,98 @@ func (r *ForecastRepository) MarkForecastApproved(forecastID uuid.UUID) error {
 
 func (r *ForecastRepository) getOutcomesByForecastID(forecastID uuid.UUID) ([]model.Outcome, error) {
 	var outcomes []model.Outcome
-	err := r.DB.Select(&outcomes, `SELECT id, forecast_id, content, confidence_level FROM outcome WHERE forecast_id = $1`, forecastID)
-	return outcomes, err
+	err := r.DB.Select(&outcomes, `SELECT id, forecast_id, content, confidence_level, rationale FROM outcome WHERE forecast_id = $1`, forecastID)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomeSources []model.OutcomeSource
+	sourceQuery := `
+        SELECT os.outcome_id, os.source_id
+        FROM outcome_source os
+        JOIN outcome o ON o.id = os.outcome_id
+        WHERE o.forecast_id = $1
+    `
+	err = r.DB.Select(&outcomeSources, sourceQuery, forecastID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outcome sources: %v", err)
+	}
+
+	var contributions []model.OutcomeContribution
+	query := `
+        SELECT oc.id, oc.outcome_id, oc.model, oc.content, oc.confidence_level
+        FROM outcome_contribution oc
+        JOIN outcome o ON o.id = oc.outcome_id
+        WHERE o.forecast_id = $1
+        ORDER BY oc.model
+    `
+	err = r.DB.Select(&contributions, query, forecastID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outcome contributions: %v", err)
+	}
+
+	for i := range outcomes {
+		for _, link := range outcomeSources {
+			if link.OutcomeID == outcomes[i].ID {
+				outcomes[i].SourceIDs = append(outcomes[i].SourceIDs, link.SourceID)
+			}
+		}
+		for _, c := range contributions {
+			if c.OutcomeID == outcomes[i].ID {
+				outcomes[i].Contributions = append(outcomes[i].Contributions, c)
+			}
+		}
+	}
+
+	return outcomes, nil
+}
+
+func insertEmbedding(db sqlx.Execer, forecastID uuid.UUID, e *model.ForecastEmbedding) error {
+	query := `
+        INSERT INTO forecast_embedding (forecast_id, model, embedding, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (forecast_id, model)
+        DO UPDATE SET embedding = EXCLUDED.embedding, created_at = EXCLUDED.created_at
+    `
+	_, err := db.Exec(query, forecastID, e.Model, e.Embedding, e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert forecast embedding: %v", err)
+	}
+	return nil
+}
+
+// insertOutcomeSources links each outcome to the sources it cites.
+func insertOutcomeSources(tx *sqlx.Tx, outcomes []model.Outcome) error {
+	query := `
+        INSERT INTO outcome_source (outcome_id, source_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `
+	for _, o := range outcomes {
+		for _, sourceID := range o.SourceIDs {
+			_, err := tx.Exec(query, o.ID, sourceID)
+			if err != nil {
+				return fmt.Errorf("failed to insert outcome source: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// insertContributions stores the per-model outcomes an ensemble forecast was
+// merged from.
+func insertContributions(tx *sqlx.Tx, contributions []model.OutcomeContribution) error {
+	query := `
+        INSERT INTO outcome_contribution (id, outcome_id, model, content, confidence_level)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	for _, c := range contributions {
+		_, err := tx.Exec(query, c.ID, c.OutcomeID, c.Model, c.Content, c.ConfidenceLevel)
+		if err != nil {
+			return fmt.Errorf("failed to insert outcome contribution: %v", err)
+		}
+	}
+	return nil
 }
 
 func (r *ForecastRepository) getTagsByForecastID(forecastID uuid.UUID) ([]model.Tag, error) {
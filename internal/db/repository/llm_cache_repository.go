@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/qoentz/evedict/internal/db/model"
+)
+
+type LLMCacheRepository struct {
+	DB *sqlx.DB
+}
+
+func NewLLMCacheRepository(db *sqlx.DB) *LLMCacheRepository {
+	return &LLMCacheRepository{
+		DB: db,
+	}
+}
+
+func (r *LLMCacheRepository) GetCacheEntry(templateType, modelName, promptHash string) (*model.LLMCacheEntry, error) {
+	var entry model.LLMCacheEntry
+	query := `
+        SELECT template_type, model, prompt_hash, output, created_at
+        FROM llm_cache
+        WHERE template_type = $1 AND model = $2 AND prompt_hash = $3
+    `
+	err := r.DB.Get(&entry, query, templateType, modelName, promptHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch cache entry: %v", err)
+	}
+
+	return &entry, nil
+}
+
+func (r *LLMCacheRepository) SaveCacheEntry(entry *model.LLMCacheEntry) error {
+	query := `
+        INSERT INTO llm_cache (template_type, model, prompt_hash, output, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (template_type, model, prompt_hash)
+        DO UPDATE SET
+            output = EXCLUDED.output,
+            created_at = EXCLUDED.created_at
+    `
+	_, err := r.DB.Exec(query, entry.TemplateType, entry.Model, entry.PromptHash, entry.Output, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save cache entry: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/qoentz/evedict/internal/db/model"
+)
+
+type LLMCallRepository struct {
+	DB *sqlx.DB
+}
+
+func NewLLMCallRepository(db *sqlx.DB) *LLMCallRepository {
+	return &LLMCallRepository{
+		DB: db,
+	}
+}
+
+// usageColumns aggregates llm_call rows; callers add the grouping key.
+const usageColumns = `
+            COUNT(*) AS calls,
+            COUNT(*) FILTER (WHERE status = 'failed') AS failures,
+            COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+            COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+            AVG(latency_ms) AS avg_latency_ms,
+            MAX(latency_ms) AS max_latency_ms,
+            COALESCE(SUM(estimated_cost), 0) AS estimated_cost,
+            MIN(created_at) AS started_at
+`
+
+func (r *LLMCallRepository) SaveLLMCall(call *model.LLMCall) error {
+	query := `
+        INSERT INTO llm_call (id, run_id, template_type, model, prompt_chars, output_chars, prompt_tokens,
+                              completion_tokens, latency_ms, status, error, estimated_cost, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    `
+	_, err := r.DB.Exec(query,
+		call.ID,
+		call.RunID,
+		call.TemplateType,
+		call.Model,
+		call.PromptChars,
+		call.OutputChars,
+		call.PromptTokens,
+		call.CompletionTokens,
+		call.LatencyMs,
+		call.Status,
+		call.Error,
+		call.EstimatedCost,
+		call.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert llm call: %v", err)
+	}
+
+	return nil
+}
+
+func (r *LLMCallRepository) GetUsageByDay(days int) ([]model.LLMUsageSummary, error) {
+	var summaries []model.LLMUsageSummary
+	query := `
+        SELECT TO_CHAR(DATE_TRUNC('day', created_at), 'YYYY-MM-DD') AS key,` + usageColumns + `
+        FROM llm_call
+        WHERE created_at >= NOW() - MAKE_INTERVAL(days => $1)
+        GROUP BY key
+        ORDER BY key DESC
+    `
+	if err := r.DB.Select(&summaries, query, days); err != nil {
+		return nil, fmt.Errorf("failed to fetch usage by day: %v", err)
+	}
+
+	return summaries, nil
+}
+
+func (r *LLMCallRepository) GetUsageByTemplate(days int) ([]model.LLMUsageSummary, error) {
+	var summaries []model.LLMUsageSummary
+	query := `
+        SELECT template_type AS key,` + usageColumns + `
+        FROM llm_call
+        WHERE created_at >= NOW() - MAKE_INTERVAL(days => $1)
+        GROUP BY key
+        ORDER BY estimated_cost DESC, calls DESC
+    `
+	if err := r.DB.Select(&summaries, query, days); err != nil {
+		return nil, fmt.Errorf("failed to fetch usage by template: %v", err)
+	}
+
+	return summaries, nil
+}
+
+func (r *LLMCallRepository) GetUsageByRun(limit int) ([]model.LLMUsageSummary, error) {
+	var summaries []model.LLMUsageSummary
+	query := `
+        SELECT run_id::TEXT AS key,` + usageColumns + `
+        FROM llm_call
+        WHERE run_id IS NOT NULL
+        GROUP BY run_id
+        ORDER BY started_at DESC
+        LIMIT $1
+    `
+	if err := r.DB.Select(&summaries, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to fetch usage by run: %v", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,92 @@
+package eventfeed
+
+import (
+	"strings"
+	"time"
+)
+
+// Article is a news article in the shape every Source returns. The field
+// names are the ones prompt templates refer to, so they follow NewsAPI, the
+// first feed.
+type Article struct {
+	// Feed is the name of the Source the article came from
+	Feed        string    `json:"feed,omitempty"`
+	Source      Publisher `json:"source"`
+	Author      string    `json:"author"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	URLToImage  string    `json:"urlToImage"`
+	// PublishedAt is in RFC 3339, UTC, or empty when the feed doesn't say
+	PublishedAt string `json:"publishedAt"`
+	// Content is the article body, often only its beginning
+	Content string `json:"content"`
+	// Language is the ISO 639-1 code of the article text, when known.
+	Language string `json:"language,omitempty"`
+}
+
+// Publisher is the outlet that published an article.
+type Publisher struct {
+	ID   *string `json:"id"`
+	Name string  `json:"name"`
+}
+
+// Published returns PublishedAt as a time, or the zero time when it is
+// missing.
+func (a Article) Published() time.Time {
+	t, err := time.Parse(time.RFC3339, a.PublishedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Event is a prediction market event with its markets.
+type Event struct {
+	// Feed is the name of the EventSource the event came from
+	Feed        string   `json:"feed,omitempty"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	StartDate   string   `json:"startDate"`
+	Image       string   `json:"image"`
+	Volume      float64  `json:"volume"`
+	Tags        []Tag    `json:"tags"`
+	Markets     []Market `json:"markets"`
+}
+
+type Tag struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type Market struct {
+	ID            string  `json:"id"`
+	Question      string  `json:"question"`
+	Description   string  `json:"description"`
+	Outcomes      string  `json:"outcomes"` // A JSON list like "[\"Yes\",\"No\"]"
+	OutcomePrices string  `json:"outcomePrices"`
+	Volume        string  `json:"volume"`
+	VolumeNum     float64 `json:"volumeNum"`
+	Featured      bool    `json:"featured"`
+	Active        bool    `json:"active"`
+	Closed        bool    `json:"closed"`
+}
+
+// NormalizeTime parses a feed timestamp in RFC 3339 or one of layouts and
+// returns it in RFC 3339, UTC. Timestamps it can't parse become empty, so a
+// feed's own format never reaches the prompts.
+func NormalizeTime(value string, layouts ...string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	for _, layout := range append([]string{time.RFC3339}, layouts...) {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return ""
+}
@@ -1,13 +1,44 @@
 package gdelt
 
+import (
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"strings"
+)
+
 type Article struct {
-	Title    string `json:"title"`
-	URL      string `json:"url"`
-	SeenDate string `json:"seendate"`
-	Domain   string `json:"domain"`
-	Language string `json:"language"`
+	Title         string `json:"title"`
+	URL           string `json:"url"`
+	SocialImage   string `json:"socialimage"`
+	SeenDate      string `json:"seendate"`
+	Domain        string `json:"domain"`
+	Language      string `json:"language"`
+	SourceCountry string `json:"sourcecountry"`
 }
 
 type Response struct {
 	Articles []Article `json:"articles"`
 }
+
+// seenDateLayouts are the formats GDELT has been seen to write the time it
+// first saw an article in. The documented one comes first.
+var seenDateLayouts = []string{
+	"20060102T150405Z",
+	"20060102T150405",
+	"20060102150405",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// toArticle maps an article of the list. GDELT lists no description, so it
+// is left empty.
+func (a Article) toArticle() eventfeed.Article {
+	return eventfeed.Article{
+		Feed:        feedName,
+		Source:      eventfeed.Publisher{Name: a.Domain},
+		Title:       strings.TrimSpace(a.Title),
+		URL:         a.URL,
+		URLToImage:  a.SocialImage,
+		PublishedAt: eventfeed.NormalizeTime(a.SeenDate, seenDateLayouts...),
+		Language:    languageCode(a.Language),
+	}
+}
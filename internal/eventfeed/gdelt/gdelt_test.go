@@ -0,0 +1,125 @@
+package gdelt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArticleToArticle(t *testing.T) {
+	tests := []struct {
+		name     string
+		seenDate string
+		want     string
+	}{
+		{"documented layout", "20250801T123000Z", "2025-08-01T12:30:00Z"},
+		{"without zone", "20250801T123000", "2025-08-01T12:30:00Z"},
+		{"digits only", "20250801123000", "2025-08-01T12:30:00Z"},
+		{"rfc 3339", "2025-08-01T14:30:00+02:00", "2025-08-01T12:30:00Z"},
+		{"unparseable", "yesterday", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := Article{
+				Title:       " Fed holds rates ",
+				URL:         "https://example.com/a",
+				SocialImage: "https://example.com/a.jpg",
+				SeenDate:    tt.seenDate,
+				Domain:      "example.com",
+				Language:    "Spanish",
+			}.toArticle()
+
+			if article.PublishedAt != tt.want {
+				t.Errorf("PublishedAt = %q, want %q", article.PublishedAt, tt.want)
+			}
+			if article.Title != "Fed holds rates" || article.Description != "" {
+				t.Errorf("Title = %q, Description = %q, want the trimmed title and no description", article.Title, article.Description)
+			}
+			if article.URLToImage != "https://example.com/a.jpg" {
+				t.Errorf("URLToImage = %q", article.URLToImage)
+			}
+			if article.Language != "es" {
+				t.Errorf("Language = %q, want es", article.Language)
+			}
+			if article.Feed != feedName || article.Source.Name != "example.com" {
+				t.Errorf("Feed = %q, Source = %q", article.Feed, article.Source.Name)
+			}
+		})
+	}
+}
+
+func TestQueryExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"single keyword", Query{Keywords: []string{"bitcoin"}}, "bitcoin"},
+		{"keywords are or'ed", Query{Keywords: []string{"Fed", "interest rates"}}, `(Fed OR "interest rates")`},
+		{"short and syntax only keywords are dropped", Query{Keywords: []string{"AI", "(x)", "tariffs"}}, "tariffs"},
+		{"syntax characters are removed", Query{Keywords: []string{`"Trump-Xi" talks`}}, `"Trump Xi talks"`},
+		{"filters", Query{Keywords: []string{"election"}, SourceCountry: "United States", SourceLang: "English"}, "election sourcecountry:unitedstates sourcelang:english"},
+		{"filters only", Query{SourceLang: "eng"}, "sourcelang:eng"},
+		{"empty", Query{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Expression(); got != tt.want {
+				t.Errorf("Expression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryWithDefaults(t *testing.T) {
+	q := Query{Timespan: "24h"}.withDefaults(Query{Timespan: "7d", SourceLang: "english", MaxRecords: 50})
+	if q.Timespan != "24h" || q.SourceLang != "english" || q.MaxRecords != 50 {
+		t.Errorf("withDefaults() = %+v", q)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    int
+		wantErr string
+	}{
+		{"articles", `{"articles":[{"title":"A","url":"https://example.com/a","seendate":"20250801T123000Z"}]}`, 1, ""},
+		{"no results", `{}`, 0, ""},
+		{"empty body", ``, 0, ""},
+		{"query error as text", `The specified phrase is too short.`, 0, "rejected the query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("mode") != string(ArticleList) {
+					t.Errorf("mode = %q", r.URL.Query().Get("mode"))
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := NewGDELTService(server.Client(), server.URL, Query{})
+			articles, err := s.FetchWithKeywords(context.Background(), []string{"election"})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(articles) != tt.want {
+				t.Errorf("got %d articles, want %d", len(articles), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gdelt
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// minKeywordLength is the shortest keyword GDELT accepts; it rejects the whole
+// query for a shorter one.
+const minKeywordLength = 3
+
+// Query is a search of the GDELT DOC 2.0 API. Empty fields are left out.
+type Query struct {
+	// Keywords match when any of them is in the article; keywords with
+	// spaces are searched as phrases
+	Keywords []string
+	// Timespan is how far back to search, e.g. "24h", "3d" or "2w"
+	Timespan string
+	// SourceCountry is a country name or FIPS code, e.g. "US"
+	SourceCountry string
+	// SourceLang is a language name or code, e.g. "english" or "eng"
+	SourceLang string
+	MaxRecords int
+	Sort       Sort
+}
+
+// withDefaults fills the fields q leaves empty from defaults.
+func (q Query) withDefaults(defaults Query) Query {
+	if q.Timespan == "" {
+		q.Timespan = defaults.Timespan
+	}
+	if q.SourceCountry == "" {
+		q.SourceCountry = defaults.SourceCountry
+	}
+	if q.SourceLang == "" {
+		q.SourceLang = defaults.SourceLang
+	}
+	if q.MaxRecords == 0 {
+		q.MaxRecords = defaults.MaxRecords
+	}
+	if q.Sort == "" {
+		q.Sort = defaults.Sort
+	}
+	return q
+}
+
+// Expression is the query parameter: the keywords and the source filters in
+// GDELT's search syntax.
+func (q Query) Expression() string {
+	var parts []string
+
+	terms := q.terms()
+	switch {
+	case len(terms) == 1:
+		parts = append(parts, terms[0])
+	case len(terms) > 1:
+		// GDELT only accepts OR inside parentheses
+		parts = append(parts, "("+strings.Join(terms, " OR ")+")")
+	}
+
+	if country := filterValue(q.SourceCountry); country != "" {
+		parts = append(parts, "sourcecountry:"+country)
+	}
+	if lang := filterValue(q.SourceLang); lang != "" {
+		parts = append(parts, "sourcelang:"+lang)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// terms are the keywords GDELT accepts, in its syntax.
+func (q Query) terms() []string {
+	var terms []string
+	for _, keyword := range q.Keywords {
+		if term := keywordTerm(keyword); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Params are the URL parameters of the query in article list mode.
+func (q Query) Params() map[string]string {
+	params := map[string]string{
+		"query":  q.Expression(),
+		"mode":   string(ArticleList),
+		"format": "json",
+	}
+	if q.Timespan != "" {
+		params["timespan"] = q.Timespan
+	}
+	if q.MaxRecords > 0 {
+		params["maxrecords"] = strconv.Itoa(q.MaxRecords)
+	}
+	if q.Sort != "" {
+		params["sort"] = string(q.Sort)
+	}
+	return params
+}
+
+// keywordTerm quotes a keyword as a phrase when needed. Characters that have a
+// meaning in the query syntax are dropped, and keywords GDELT would reject as
+// too short become empty.
+func keywordTerm(keyword string) string {
+	keyword = strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '(', ')', ':', '-':
+			return ' '
+		}
+		return r
+	}, keyword)
+	keyword = strings.Join(strings.Fields(keyword), " ")
+
+	if utf8.RuneCountInString(keyword) < minKeywordLength {
+		return ""
+	}
+	if strings.Contains(keyword, " ") {
+		return `"` + keyword + `"`
+	}
+	return keyword
+}
+
+// filterValue turns a country or language name into the single word GDELT
+// expects, e.g. "United States" into "unitedstates".
+func filterValue(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), ""))
+}
@@ -1,32 +1,229 @@
 package gdelt
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/qoentz/evedict/internal/llm/hugface"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"io"
 	"log"
-	"os"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-func CreatePromptFromHeadlines(articles []Article) string {
-	var headlines []string
-	for _, article := range articles {
-		if article.Language != "English" {
-			translatedTitle, err := hugface.TranslateHeadline(article.Title)
-			if err != nil {
-				log.Printf("Error translating headline: %v", err)
-				continue
-			}
-			fmt.Println(translatedTitle)
-			headlines = append(headlines, translatedTitle)
-		} else {
-			fmt.Println(article.Title)
-			headlines = append(headlines, article.Title)
+const (
+	// requestInterval is the pace GDELT asks clients to keep
+	requestInterval = 5 * time.Second
+	// maxRetries is how often a rate limited request is retried
+	maxRetries = 3
+
+	headlineTimespan = "24h"
+	headlineRecords  = 20
+	searchTimespan   = "7d"
+	searchRecords    = 10
+)
+
+type Service struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	// Defaults fill what a query leaves empty, e.g. a source language
+	Defaults Query
+
+	mu          sync.Mutex
+	nextRequest time.Time
+}
+
+func NewGDELTService(client *http.Client, baseURL string, defaults Query) *Service {
+	return &Service{
+		HTTPClient: client,
+		BaseURL:    baseURL,
+		Defaults:   defaults,
+	}
+}
+
+func (s *Service) Name() string {
+	return feedName
+}
+
+// FetchTopHeadlines returns the most relevant articles of the last day about
+// the category. GDELT has no categories, so they are searched by keywords.
+func (s *Service) FetchTopHeadlines(ctx context.Context, category eventfeed.Category) ([]eventfeed.Article, error) {
+	keywords, ok := categoryKeywords[category]
+	if !ok {
+		return nil, nil
+	}
+
+	return s.Search(ctx, Query{
+		Keywords:   keywords,
+		Timespan:   headlineTimespan,
+		MaxRecords: headlineRecords,
+		Sort:       HybridRel,
+	})
+}
+
+func (s *Service) FetchWithKeywords(ctx context.Context, keywords []string) ([]eventfeed.Article, error) {
+	query := Query{
+		Keywords:   keywords,
+		Timespan:   searchTimespan,
+		MaxRecords: searchRecords,
+		Sort:       HybridRel,
+	}
+	if len(query.terms()) == 0 {
+		return nil, fmt.Errorf("no keywords provided")
+	}
+
+	return s.Search(ctx, query)
+}
+
+// Search runs a query in article list mode.
+func (s *Service) Search(ctx context.Context, query Query) ([]eventfeed.Article, error) {
+	query = query.withDefaults(s.Defaults)
+	if query.Expression() == "" {
+		return nil, fmt.Errorf("empty GDELT query")
+	}
+
+	path, err := s.ConstructURL(query.Params())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Fetch(ctx, path)
+}
+
+func (s *Service) ConstructURL(params map[string]string) (string, error) {
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Fetch requests url at the pace GDELT allows and retries when it reports
+// being rate limited anyway, e.g. because of another client on the same IP.
+func (s *Service) Fetch(ctx context.Context, url string) ([]eventfeed.Article, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		articles, backoff, err := s.fetch(ctx, url)
+		if backoff == 0 || attempt == maxRetries {
+			return articles, err
+		}
+
+		log.Printf("GDELT rate limited the request, retrying in %s", backoff)
+		s.delay(backoff)
+	}
+}
+
+// fetch makes a single request. A positive duration means GDELT rate limited
+// it and it may be retried after that long.
+func (s *Service) fetch(ctx context.Context, url string) ([]eventfeed.Article, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, retryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("API rate limited the request: %s", resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("API returned status: %s, message: %s", resp.Status, string(respBody))
+	}
+
+	respBody = bytes.TrimSpace(respBody)
+	if len(respBody) == 0 {
+		return nil, 0, nil
+	}
+	// Rate limits and query errors may come back as plain text with status 200
+	if respBody[0] != '{' {
+		if isRateLimitMessage(respBody) {
+			return nil, retryAfter(""), fmt.Errorf("API rate limited the request: %s", respBody)
 		}
+		return nil, 0, fmt.Errorf("API rejected the query: %s", respBody)
 	}
 
-	prompt := os.Getenv("PROMPT_INPUT")
-	prompt += strings.Join(headlines, "\n- ")
+	var data Response
+	if err = json.Unmarshal(respBody, &data); err != nil {
+		return nil, 0, err
+	}
 
-	return prompt
+	articles := make([]eventfeed.Article, len(data.Articles))
+	for i, article := range data.Articles {
+		articles[i] = article.toArticle()
+	}
+
+	return articles, 0, nil
+}
+
+// wait blocks until the next request is allowed and reserves its slot.
+func (s *Service) wait(ctx context.Context) error {
+	s.mu.Lock()
+	now := time.Now()
+	start := s.nextRequest
+	if start.Before(now) {
+		start = now
+	}
+	s.nextRequest = start.Add(requestInterval)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay pushes the next request back by d.
+func (s *Service) delay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if next := time.Now().Add(d); next.After(s.nextRequest) {
+		s.nextRequest = next
+	}
+}
+
+func isRateLimitMessage(body []byte) bool {
+	text := strings.ToLower(string(body))
+	return strings.Contains(text, "limit requests") || strings.Contains(text, "rate limit")
+}
+
+// retryAfter reads a Retry-After header in seconds, falling back to twice the
+// request interval.
+func retryAfter(header string) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 2 * requestInterval
 }
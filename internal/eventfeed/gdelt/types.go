@@ -0,0 +1,108 @@
+package gdelt
+
+import (
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"strings"
+)
+
+const feedName = "gdelt"
+
+type Mode string
+
+const (
+	ArticleList Mode = "artlist"
+)
+
+type Sort string
+
+const (
+	// HybridRel ranks by relevance with a boost for recent and prominent
+	// coverage
+	HybridRel Sort = "hybridrel"
+	DateDesc  Sort = "datedesc"
+)
+
+// categoryKeywords stand in for the categories GDELT doesn't have. Headlines
+// of a category are the most relevant recent articles matching any of them.
+var categoryKeywords = map[eventfeed.Category][]string{
+	eventfeed.Business:      {"economy", "stock market", "inflation", "central bank", "earnings"},
+	eventfeed.Entertainment: {"film", "music", "celebrity", "box office", "streaming"},
+	eventfeed.General:       {"government", "election", "president", "parliament", "war"},
+	eventfeed.Health:        {"health", "disease", "vaccine", "hospital", "outbreak"},
+	eventfeed.Science:       {"science", "research", "space", "climate", "scientists"},
+	eventfeed.Sports:        {"football", "basketball", "tennis", "championship", "olympics"},
+	eventfeed.Technology:    {"technology", "artificial intelligence", "software", "semiconductor", "cybersecurity"},
+}
+
+// languages maps the language names GDELT reports to ISO 639-1 codes.
+var languages = map[string]string{
+	"afrikaans":   "af",
+	"albanian":    "sq",
+	"arabic":      "ar",
+	"armenian":    "hy",
+	"azerbaijani": "az",
+	"bengali":     "bn",
+	"bosnian":     "bs",
+	"bulgarian":   "bg",
+	"catalan":     "ca",
+	"chinese":     "zh",
+	"croatian":    "hr",
+	"czech":       "cs",
+	"danish":      "da",
+	"dutch":       "nl",
+	"english":     "en",
+	"estonian":    "et",
+	"finnish":     "fi",
+	"french":      "fr",
+	"galician":    "gl",
+	"georgian":    "ka",
+	"german":      "de",
+	"greek":       "el",
+	"gujarati":    "gu",
+	"hebrew":      "he",
+	"hindi":       "hi",
+	"hungarian":   "hu",
+	"icelandic":   "is",
+	"indonesian":  "id",
+	"italian":     "it",
+	"japanese":    "ja",
+	"kannada":     "kn",
+	"kazakh":      "kk",
+	"korean":      "ko",
+	"latvian":     "lv",
+	"lithuanian":  "lt",
+	"macedonian":  "mk",
+	"malay":       "ms",
+	"malayalam":   "ml",
+	"marathi":     "mr",
+	"mongolian":   "mn",
+	"nepali":      "ne",
+	"norwegian":   "no",
+	"persian":     "fa",
+	"polish":      "pl",
+	"portuguese":  "pt",
+	"punjabi":     "pa",
+	"romanian":    "ro",
+	"russian":     "ru",
+	"serbian":     "sr",
+	"sinhalese":   "si",
+	"slovak":      "sk",
+	"slovenian":   "sl",
+	"somali":      "so",
+	"spanish":     "es",
+	"swahili":     "sw",
+	"swedish":     "sv",
+	"tamil":       "ta",
+	"telugu":      "te",
+	"thai":        "th",
+	"turkish":     "tr",
+	"ukrainian":   "uk",
+	"urdu":        "ur",
+	"vietnamese":  "vi",
+}
+
+// languageCode maps the language names GDELT reports, e.g. "Spanish", to
+// ISO 639-1 codes. Unknown names are left to language detection.
+func languageCode(name string) string {
+	return languages[strings.ToLower(strings.TrimSpace(name))]
+}
@@ -0,0 +1,134 @@
+package eventfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// MultiSource queries several sources at once and merges their articles. It
+// fails only when every source fails; the errors of the others are logged.
+type MultiSource struct {
+	Sources []Source
+}
+
+func NewMultiSource(sources ...Source) *MultiSource {
+	return &MultiSource{
+		Sources: sources,
+	}
+}
+
+func (m *MultiSource) Name() string {
+	names := make([]string, len(m.Sources))
+	for i, source := range m.Sources {
+		names[i] = source.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+func (m *MultiSource) FetchTopHeadlines(ctx context.Context, category Category) ([]Article, error) {
+	return m.fetch(func(source Source) ([]Article, error) {
+		return source.FetchTopHeadlines(ctx, category)
+	})
+}
+
+func (m *MultiSource) FetchWithKeywords(ctx context.Context, keywords []string) ([]Article, error) {
+	return m.fetch(func(source Source) ([]Article, error) {
+		return source.FetchWithKeywords(ctx, keywords)
+	})
+}
+
+func (m *MultiSource) fetch(fetch func(source Source) ([]Article, error)) ([]Article, error) {
+	if len(m.Sources) == 0 {
+		return nil, fmt.Errorf("no news sources configured")
+	}
+
+	results := make([][]Article, len(m.Sources))
+	errs := make([]error, len(m.Sources))
+
+	var wg sync.WaitGroup
+	for i, source := range m.Sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%s: %v", m.Sources[i].Name(), err))
+		}
+	}
+	if len(failed) == len(m.Sources) {
+		return nil, errors.Join(failed...)
+	}
+	for _, err := range failed {
+		log.Printf("Error fetching articles, continuing with the other sources: %v", err)
+	}
+
+	return interleave(results), nil
+}
+
+// interleave merges the results of several sources by taking their articles
+// in turn, so each source's own ranking is kept and none crowds out the
+// rest. An article reported by more than one source is kept once.
+func interleave(results [][]Article) []Article {
+	var merged []Article
+	seen := map[string]bool{}
+
+	for i := 0; ; i++ {
+		added := false
+		for _, articles := range results {
+			if i >= len(articles) {
+				continue
+			}
+			added = true
+
+			article := articles[i]
+			keys := dedupKeys(article)
+			duplicate := false
+			for _, key := range keys {
+				duplicate = duplicate || seen[key]
+			}
+			if duplicate {
+				continue
+			}
+			for _, key := range keys {
+				seen[key] = true
+			}
+			merged = append(merged, article)
+		}
+		if !added {
+			return merged
+		}
+	}
+}
+
+func dedupKeys(article Article) []string {
+	var keys []string
+	if u := normalizeURL(article.URL); u != "" {
+		keys = append(keys, "url:"+u)
+	}
+	if title := strings.ToLower(strings.TrimSpace(article.Title)); title != "" {
+		keys = append(keys, "title:"+title)
+	}
+	return keys
+}
+
+// normalizeURL drops what commonly differs between copies of the same link.
+func normalizeURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	u = strings.TrimPrefix(u, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	u = strings.TrimPrefix(u, "www.")
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	return strings.TrimSuffix(u, "/")
+}
@@ -0,0 +1,105 @@
+package eventfeed
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// staticSource returns the same articles, or error, for every query.
+type staticSource struct {
+	name     string
+	articles []Article
+	err      error
+}
+
+func (s staticSource) Name() string {
+	return s.name
+}
+
+func (s staticSource) FetchTopHeadlines(context.Context, Category) ([]Article, error) {
+	return s.articles, s.err
+}
+
+func (s staticSource) FetchWithKeywords(context.Context, []string) ([]Article, error) {
+	return s.articles, s.err
+}
+
+func titles(articles []Article) []string {
+	t := make([]string, len(articles))
+	for i, article := range articles {
+		t[i] = article.Title
+	}
+	return t
+}
+
+func TestMultiSource(t *testing.T) {
+	a := staticSource{name: "a", articles: []Article{
+		{Title: "Fed holds rates", URL: "https://www.example.com/fed?utm=a"},
+		{Title: "Oil falls", URL: "https://example.com/oil"},
+		{Title: "Storm nears coast", URL: "https://example.com/storm"},
+	}}
+	b := staticSource{name: "b", articles: []Article{
+		{Title: "Fed keeps rates unchanged", URL: "http://example.com/fed/"},
+		{Title: "OIL FALLS ", URL: "https://other.org/oil"},
+		{Title: "Election results"},
+	}}
+	down := staticSource{name: "down", err: errors.New("unavailable")}
+
+	tests := []struct {
+		name    string
+		sources []Source
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "interleaved without duplicates",
+			sources: []Source{a, b},
+			want:    []string{"Fed holds rates", "Oil falls", "Storm nears coast", "Election results"},
+		},
+		{
+			name:    "failing source skipped",
+			sources: []Source{down, b},
+			want:    []string{"Fed keeps rates unchanged", "OIL FALLS ", "Election results"},
+		},
+		{
+			name:    "every source failing",
+			sources: []Source{down, down},
+			wantErr: true,
+		},
+		{
+			name:    "no sources",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMultiSource(tt.sources...)
+
+			for _, fetch := range []func() ([]Article, error){
+				func() ([]Article, error) { return m.FetchTopHeadlines(context.Background(), General) },
+				func() ([]Article, error) { return m.FetchWithKeywords(context.Background(), []string{"fed"}) },
+			} {
+				articles, err := fetch()
+				if tt.wantErr {
+					if err == nil {
+						t.Error("fetch returned no error")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("fetch error: %v", err)
+				}
+				if got := titles(articles); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("titles = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if name := NewMultiSource(a, b).Name(); name != "a+b" {
+		t.Errorf("Name() = %q, want a+b", name)
+	}
+}
@@ -1,5 +1,7 @@
 package newsapi
 
+import "github.com/qoentz/evedict/internal/eventfeed"
+
 type Response struct {
 	Status       string    `json:"status"`
 	TotalResults int       `json:"totalResults"`
@@ -21,3 +23,17 @@ type Source struct {
 	ID   *string `json:"id"`
 	Name string  `json:"name"`
 }
+
+func (a Article) toArticle() eventfeed.Article {
+	return eventfeed.Article{
+		Feed:        feedName,
+		Source:      eventfeed.Publisher{ID: a.Source.ID, Name: a.Source.Name},
+		Author:      a.Author,
+		Title:       a.Title,
+		Description: a.Description,
+		URL:         a.URL,
+		URLToImage:  a.URLToImage,
+		PublishedAt: eventfeed.NormalizeTime(a.PublishedAt),
+		Content:     a.Content,
+	}
+}
@@ -1,8 +1,10 @@
 package newsapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"io"
 	"net/http"
 	"net/url"
@@ -23,7 +25,11 @@ func NewNewsAPIService(client *http.Client, apiKey, baseURL string) *Service {
 	}
 }
 
-func (s *Service) FetchTopHeadlines(category Category) ([]Article, error) {
+func (s *Service) Name() string {
+	return feedName
+}
+
+func (s *Service) FetchTopHeadlines(ctx context.Context, category eventfeed.Category) ([]eventfeed.Article, error) {
 	params := map[string]string{
 		"category": string(category),
 	}
@@ -33,7 +39,7 @@ func (s *Service) FetchTopHeadlines(category Category) ([]Article, error) {
 		return nil, err
 	}
 
-	articles, err := s.Fetch(path)
+	articles, err := s.Fetch(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +47,7 @@ func (s *Service) FetchTopHeadlines(category Category) ([]Article, error) {
 	return articles, nil
 }
 
-func (s *Service) FetchWithKeywords(keywords []string) ([]Article, error) {
+func (s *Service) FetchWithKeywords(ctx context.Context, keywords []string) ([]eventfeed.Article, error) {
 	if len(keywords) == 0 {
 		return nil, fmt.Errorf("no keywords provided")
 	}
@@ -59,7 +65,7 @@ func (s *Service) FetchWithKeywords(keywords []string) ([]Article, error) {
 		return nil, err
 	}
 
-	articles, err := s.Fetch(path)
+	articles, err := s.Fetch(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -87,8 +93,8 @@ func (s *Service) ConstructURL(endpoint Endpoint, params map[string]string) (str
 	return u.String(), nil
 }
 
-func (s *Service) Fetch(url string) ([]Article, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (s *Service) Fetch(ctx context.Context, url string) ([]eventfeed.Article, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -118,14 +124,10 @@ func (s *Service) Fetch(url string) ([]Article, error) {
 		return nil, err
 	}
 
-	return data.Articles, nil
-}
-
-func ValidateCategory(category string) (Category, error) {
-	switch Category(category) {
-	case Business, Entertainment, General, Health, Science, Sports, Technology:
-		return Category(category), nil
-	default:
-		return "", fmt.Errorf("invalid category: %s", category)
+	articles := make([]eventfeed.Article, len(data.Articles))
+	for i, article := range data.Articles {
+		articles[i] = article.toArticle()
 	}
+
+	return articles, nil
 }
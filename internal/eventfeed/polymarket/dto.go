@@ -1,5 +1,9 @@
 package polymarket
 
+import "github.com/qoentz/evedict/internal/eventfeed"
+
+const feedName = "polymarket"
+
 type Event struct {
 	ID          string   `json:"id"`
 	Title       string   `json:"title"`
@@ -28,3 +32,38 @@ type Market struct {
 	Active        bool    `json:"active"`
 	Closed        bool    `json:"closed"`
 }
+
+func (e Event) toEvent() eventfeed.Event {
+	tags := make([]eventfeed.Tag, len(e.Tags))
+	for i, tag := range e.Tags {
+		tags[i] = eventfeed.Tag{ID: tag.ID, Label: tag.Label}
+	}
+
+	markets := make([]eventfeed.Market, len(e.Markets))
+	for i, m := range e.Markets {
+		markets[i] = eventfeed.Market{
+			ID:            m.ID,
+			Question:      m.Question,
+			Description:   m.Description,
+			Outcomes:      m.Outcomes,
+			OutcomePrices: m.OutcomePrices,
+			Volume:        m.Volume,
+			VolumeNum:     m.VolumeNum,
+			Featured:      m.Featured,
+			Active:        m.Active,
+			Closed:        m.Closed,
+		}
+	}
+
+	return eventfeed.Event{
+		Feed:        feedName,
+		ID:          e.ID,
+		Title:       e.Title,
+		Description: e.Description,
+		StartDate:   eventfeed.NormalizeTime(e.StartDate),
+		Image:       e.Image,
+		Volume:      e.Volume,
+		Tags:        tags,
+		Markets:     markets,
+	}
+}
@@ -1,8 +1,10 @@
 package polymarket
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"io"
 	"net/http"
 	"time"
@@ -20,7 +22,11 @@ func NewPolyMarketService(client *http.Client, baseURL string) *Service {
 	}
 }
 
-func (s *Service) FetchTopEvents() ([]Event, error) {
+func (s *Service) Name() string {
+	return feedName
+}
+
+func (s *Service) FetchTopEvents(ctx context.Context) ([]eventfeed.Event, error) {
 	weekAgo := time.Now().UTC().AddDate(0, 0, -7)
 	startDate := weekAgo.Format("2006-01-02T15:04:05Z")
 
@@ -30,15 +36,15 @@ func (s *Service) FetchTopEvents() ([]Event, error) {
 		startDate,
 	)
 
-	events, err := s.Fetch(url)
+	events, err := s.Fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (s *Service) Fetch(url string) ([]Event, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (s *Service) Fetch(ctx context.Context, url string) ([]eventfeed.Event, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -68,5 +74,10 @@ func (s *Service) Fetch(url string) ([]Event, error) {
 		return nil, err
 	}
 
-	return data, nil
+	events := make([]eventfeed.Event, len(data))
+	for i, event := range data {
+		events[i] = event.toEvent()
+	}
+
+	return events, nil
 }
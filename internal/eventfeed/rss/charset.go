@@ -0,0 +1,42 @@
+package rss
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// windows1252 are the characters Windows-1252 puts where ISO 8859-1 has
+// control characters. Publishers labelling a feed ISO 8859-1 often mean it.
+var windows1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// charsetReader decodes the single-byte charsets older feeds still declare.
+// The XML decoder handles UTF-8 itself.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "us-ascii", "ascii", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		for _, c := range data {
+			if r, ok := windows1252[c]; ok {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune(rune(c))
+			}
+		}
+		return strings.NewReader(b.String()), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset: %s", label)
+	}
+}
@@ -0,0 +1,277 @@
+package rss
+
+import (
+	"encoding/xml"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"strings"
+	"time"
+)
+
+// Document is an RSS 2.0, RSS 1.0 or Atom feed. Only the fields of its format
+// are filled.
+type Document struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel Channel `xml:"channel"`
+	// RSS 1.0 puts its items next to the channel
+	Items []Item `xml:"item"`
+	// Atom
+	Title   string  `xml:"title"`
+	Lang    string  `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Entries []Entry `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+type Channel struct {
+	Title      string `xml:"title"`
+	Language   string `xml:"language"`
+	DCLanguage string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	Items      []Item `xml:"item"`
+}
+
+type Item struct {
+	Title          string         `xml:"title"`
+	Link           string         `xml:"link"`
+	GUID           string         `xml:"guid"`
+	Description    string         `xml:"description"`
+	ContentEncoded string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate        string         `xml:"pubDate"`
+	DCDate         string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author         string         `xml:"author"`
+	Creator        string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Enclosures     []Enclosure    `xml:"enclosure"`
+	MediaContent   []MediaElement `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail []MediaElement `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroup     []MediaElement `xml:"http://search.yahoo.com/mrss/ group>content"`
+}
+
+type Enclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type MediaElement struct {
+	URL    string `xml:"url,attr"`
+	Medium string `xml:"medium,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type Entry struct {
+	Title          string         `xml:"http://www.w3.org/2005/Atom title"`
+	Links          []Link         `xml:"http://www.w3.org/2005/Atom link"`
+	ID             string         `xml:"http://www.w3.org/2005/Atom id"`
+	Summary        string         `xml:"http://www.w3.org/2005/Atom summary"`
+	Content        string         `xml:"http://www.w3.org/2005/Atom content"`
+	Published      string         `xml:"http://www.w3.org/2005/Atom published"`
+	Updated        string         `xml:"http://www.w3.org/2005/Atom updated"`
+	Author         Person         `xml:"http://www.w3.org/2005/Atom author"`
+	Lang           string         `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	MediaThumbnail []MediaElement `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaContent   []MediaElement `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+type Person struct {
+	Name string `xml:"http://www.w3.org/2005/Atom name"`
+}
+
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// pubDateLayouts are the RFC 822 dates RSS uses, with the variations
+// publishers actually write.
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"Mon, 02 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"02 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+	"Monday, 02 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// Articles maps the items of the feed into articles, in feed order.
+func (d *Document) Articles() []eventfeed.Article {
+	var articles []eventfeed.Article
+
+	switch {
+	case len(d.Entries) > 0:
+		language := languageCode(d.Lang)
+		for _, entry := range d.Entries {
+			articles = append(articles, entry.toArticle(d.Title, language))
+		}
+	default:
+		language := languageCode(d.Channel.Language)
+		if language == "" {
+			language = languageCode(d.Channel.DCLanguage)
+		}
+		for _, item := range append(d.Channel.Items, d.Items...) {
+			articles = append(articles, item.toArticle(d.Channel.Title, language))
+		}
+	}
+
+	return articles
+}
+
+func (i Item) toArticle(feedTitle, language string) eventfeed.Article {
+	link := strings.TrimSpace(i.Link)
+	if link == "" && strings.HasPrefix(i.GUID, "http") {
+		link = strings.TrimSpace(i.GUID)
+	}
+
+	author := strings.TrimSpace(i.Creator)
+	if author == "" {
+		author = rssAuthor(i.Author)
+	}
+
+	published := i.PubDate
+	if strings.TrimSpace(published) == "" {
+		published = i.DCDate
+	}
+
+	title, content := plainText(i.Title), plainText(i.ContentEncoded)
+
+	return eventfeed.Article{
+		Feed:        feedName,
+		Source:      eventfeed.Publisher{Name: plainText(feedTitle)},
+		Author:      author,
+		Title:       title,
+		Description: description(plainText(i.Description), content, title),
+		URL:         link,
+		URLToImage:  i.image(),
+		PublishedAt: eventfeed.NormalizeTime(published, pubDateLayouts...),
+		Content:     content,
+		Language:    language,
+	}
+}
+
+func (i Item) image() string {
+	for _, enclosure := range i.Enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") && enclosure.URL != "" {
+			return enclosure.URL
+		}
+	}
+	if url := mediaImage(append(append(i.MediaContent, i.MediaGroup...), i.MediaThumbnail...)); url != "" {
+		return url
+	}
+	if url := firstImage(i.ContentEncoded); url != "" {
+		return url
+	}
+	return firstImage(i.Description)
+}
+
+func (e Entry) toArticle(feedTitle, feedLanguage string) eventfeed.Article {
+	language := languageCode(e.Lang)
+	if language == "" {
+		language = feedLanguage
+	}
+
+	published := e.Published
+	if strings.TrimSpace(published) == "" {
+		published = e.Updated
+	}
+
+	title, content := plainText(e.Title), plainText(e.Content)
+
+	return eventfeed.Article{
+		Feed:        feedName,
+		Source:      eventfeed.Publisher{Name: plainText(feedTitle)},
+		Author:      strings.TrimSpace(e.Author.Name),
+		Title:       title,
+		Description: description(plainText(e.Summary), content, title),
+		URL:         e.link(),
+		URLToImage:  e.image(),
+		PublishedAt: eventfeed.NormalizeTime(published, pubDateLayouts...),
+		Content:     content,
+		Language:    language,
+	}
+}
+
+func (e Entry) link() string {
+	for _, link := range e.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	if strings.HasPrefix(e.ID, "http") {
+		return strings.TrimSpace(e.ID)
+	}
+	return ""
+}
+
+func (e Entry) image() string {
+	for _, link := range e.Links {
+		if link.Rel == "enclosure" && strings.HasPrefix(link.Type, "image/") {
+			return link.Href
+		}
+	}
+	if url := mediaImage(append(e.MediaContent, e.MediaThumbnail...)); url != "" {
+		return url
+	}
+	if url := firstImage(e.Content); url != "" {
+		return url
+	}
+	return firstImage(e.Summary)
+}
+
+func mediaImage(elements []MediaElement) string {
+	for _, element := range elements {
+		if element.URL == "" {
+			continue
+		}
+		if element.Medium == "image" || strings.HasPrefix(element.Type, "image/") || element.Medium == "" && element.Type == "" {
+			return element.URL
+		}
+	}
+	return ""
+}
+
+// descriptionLength is how much of the content stands in for a missing
+// description
+const descriptionLength = 300
+
+// description returns the summary of an item, or when the feed has none, the
+// start of its content, or else its title.
+func description(summary, content, title string) string {
+	if summary != "" {
+		return summary
+	}
+	if content != "" {
+		if runes := []rune(content); len(runes) > descriptionLength {
+			return strings.TrimSpace(string(runes[:descriptionLength])) + "..."
+		}
+		return content
+	}
+	return title
+}
+
+// rssAuthor reads the RSS author element, an email address with the name in
+// parentheses, e.g. "jane@example.com (Jane Doe)".
+func rssAuthor(author string) string {
+	author = strings.TrimSpace(author)
+	if open := strings.Index(author, "("); open >= 0 && strings.HasSuffix(author, ")") {
+		return strings.TrimSpace(author[open+1 : len(author)-1])
+	}
+	return author
+}
+
+// languageCode reduces a language tag such as "en-us" to its ISO 639-1 code.
+func languageCode(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) != 2 {
+		return ""
+	}
+	return tag
+}
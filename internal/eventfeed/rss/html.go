@@ -0,0 +1,30 @@
+package rss
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	hiddenElements = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>`)
+	htmlTags       = regexp.MustCompile(`(?s)<[^>]*>`)
+	imageSource    = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`)
+)
+
+// plainText turns the HTML feeds put in titles and descriptions into plain
+// text on a single line.
+func plainText(text string) string {
+	text = hiddenElements.ReplaceAllString(text, " ")
+	text = htmlTags.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// firstImage returns the source of the first image in HTML.
+func firstImage(text string) string {
+	if match := imageSource.FindStringSubmatch(text); match != nil {
+		return html.UnescapeString(match[1])
+	}
+	return ""
+}
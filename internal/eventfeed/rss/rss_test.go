@@ -0,0 +1,268 @@
+package rss
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/util"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Example News</title>
+  <language>en-us</language>
+  <item>
+    <title>Fed holds rates</title>
+    <link>https://example.com/fed</link>
+    <description>&lt;p&gt;The Fed kept rates &lt;b&gt;unchanged&lt;/b&gt;.&lt;/p&gt;</description>
+    <pubDate>Fri, 1 Aug 2025 14:30:00 +0200</pubDate>
+    <author>jane@example.com (Jane Doe)</author>
+    <enclosure url="https://example.com/fed.jpg" type="image/jpeg"/>
+  </item>
+  <item>
+    <title>Tariffs rise</title>
+    <guid>https://example.com/tariffs</guid>
+    <content:encoded><![CDATA[<p>Tariffs on steel rise to 25%.</p><img src="https://example.com/steel.jpg">]]></content:encoded>
+    <dc:date>2025-08-01T12:00:00Z</dc:date>
+    <dc:creator>John Roe</dc:creator>
+  </item>
+  <item>
+    <title>Markets close higher</title>
+    <link>https://example.com/markets</link>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="de">
+  <title>Beispiel</title>
+  <entry>
+    <title>Wahl im Herbst</title>
+    <link rel="alternate" href="https://example.com/wahl"/>
+    <link rel="enclosure" type="image/png" href="https://example.com/wahl.png"/>
+    <summary>Die Wahl findet im Herbst statt.</summary>
+    <updated>2025-08-01T12:00:00Z</updated>
+    <author><name>Erika Muster</name></author>
+  </entry>
+  <entry xml:lang="en">
+    <title>Chip exports</title>
+    <id>https://example.com/chips</id>
+    <content type="html">&lt;p&gt;Chip exports fall.&lt;/p&gt;</content>
+    <published>2025-08-01T10:00:00+00:00</published>
+  </entry>
+  <entry>
+    <title>Headline only</title>
+    <link href="https://example.com/headline"/>
+  </entry>
+</feed>`
+
+func serveFeed(t *testing.T, body string) []eventfeed.Article {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	articles, err := NewRSSService(server.Client(), nil).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	return articles
+}
+
+func TestRSSToArticle(t *testing.T) {
+	articles := serveFeed(t, rssFeed)
+
+	want := []eventfeed.Article{
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Example News"},
+			Author:      "Jane Doe",
+			Title:       "Fed holds rates",
+			Description: "The Fed kept rates unchanged .",
+			URL:         "https://example.com/fed",
+			URLToImage:  "https://example.com/fed.jpg",
+			PublishedAt: "2025-08-01T12:30:00Z",
+			Language:    "en",
+		},
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Example News"},
+			Author:      "John Roe",
+			Title:       "Tariffs rise",
+			Description: "Tariffs on steel rise to 25%.",
+			URL:         "https://example.com/tariffs",
+			URLToImage:  "https://example.com/steel.jpg",
+			PublishedAt: "2025-08-01T12:00:00Z",
+			Content:     "Tariffs on steel rise to 25%.",
+			Language:    "en",
+		},
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Example News"},
+			Title:       "Markets close higher",
+			Description: "Markets close higher",
+			URL:         "https://example.com/markets",
+			Language:    "en",
+		},
+	}
+
+	compareArticles(t, articles, want)
+}
+
+func TestAtomToArticle(t *testing.T) {
+	articles := serveFeed(t, atomFeed)
+
+	want := []eventfeed.Article{
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Beispiel"},
+			Author:      "Erika Muster",
+			Title:       "Wahl im Herbst",
+			Description: "Die Wahl findet im Herbst statt.",
+			URL:         "https://example.com/wahl",
+			URLToImage:  "https://example.com/wahl.png",
+			PublishedAt: "2025-08-01T12:00:00Z",
+			Language:    "de",
+		},
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Beispiel"},
+			Title:       "Chip exports",
+			Description: "Chip exports fall.",
+			URL:         "https://example.com/chips",
+			PublishedAt: "2025-08-01T10:00:00Z",
+			Content:     "Chip exports fall.",
+			Language:    "en",
+		},
+		{
+			Feed:        feedName,
+			Source:      eventfeed.Publisher{Name: "Beispiel"},
+			Title:       "Headline only",
+			Description: "Headline only",
+			URL:         "https://example.com/headline",
+			Language:    "de",
+		},
+	}
+
+	compareArticles(t, articles, want)
+}
+
+func compareArticles(t *testing.T, got, want []eventfeed.Article) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d articles, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("article %d:\ngot  %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDescription(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+
+	tests := []struct {
+		name    string
+		summary string
+		content string
+		want    string
+	}{
+		{"summary", "Summary.", "Content.", "Summary."},
+		{"content", "", "Content.", "Content."},
+		{"long content", "", long, strings.TrimSpace(long[:descriptionLength]) + "..."},
+		{"title", "", "", "Title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := description(tt.summary, tt.content, "Title"); got != tt.want {
+				t.Errorf("description() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeywordPatterns(t *testing.T) {
+	tests := []struct {
+		keyword string
+		text    string
+		want    bool
+	}{
+		{"AI", "New AI rules", true},
+		{"AI", "He said no", false},
+		{"ai", "AI rules", true},
+		{"U.S.", "The U.S. economy", true},
+		{"U.S.", "U.S. economy", true},
+		{"U.S.", "BU.S. lines", false},
+		{"C++", "C++ turns 40", true},
+		{"C++", "Written in C++.", true},
+		{"C++", "ABC++ library", false},
+		{".NET", "Built on .NET today", true},
+		{"interest rates", "Interest rates fall", true},
+		{"rate", "Interest rates fall", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyword+" in "+tt.text, func(t *testing.T) {
+			patterns := keywordPatterns([]string{tt.keyword})
+			if len(patterns) != 1 {
+				t.Fatalf("got %d patterns, want 1", len(patterns))
+			}
+			if got := patterns[0].MatchString(tt.text); got != tt.want {
+				t.Errorf("%s matches %q = %v, want %v", patterns[0], tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if patterns := keywordPatterns([]string{"", "  "}); len(patterns) != 0 {
+		t.Errorf("got %d patterns for blank keywords, want 0", len(patterns))
+	}
+}
+
+func TestFeedCategory(t *testing.T) {
+	tests := []struct {
+		category eventfeed.Category
+		want     util.Category
+	}{
+		{eventfeed.Business, util.Economy},
+		{eventfeed.Technology, util.Technology},
+		{eventfeed.Science, util.Technology},
+		{eventfeed.Entertainment, util.Culture},
+		{eventfeed.Sports, util.Culture},
+		{eventfeed.General, util.Politics},
+		{eventfeed.Health, util.Politics},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			if got := feedCategory(tt.category); got != tt.want {
+				t.Errorf("feedCategory(%s) = %q, want %q", tt.category, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTopHeadlinesSports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(atomFeed))
+	}))
+	defer server.Close()
+
+	s := NewRSSService(server.Client(), map[util.Category][]string{util.Culture: {server.URL}})
+	headlines, err := s.FetchTopHeadlines(context.Background(), eventfeed.Sports)
+	if err != nil {
+		t.Fatalf("FetchTopHeadlines() error: %v", err)
+	}
+	// Only the undated entry is recent enough
+	if len(headlines) != 1 || headlines[0].Title != "Headline only" {
+		t.Errorf("got %+v, want the undated entry", headlines)
+	}
+}
@@ -0,0 +1,303 @@
+package rss
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/util"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	feedName = "rss"
+
+	headlineRecords = 20
+	searchRecords   = 10
+	// headlineMaxAge keeps old items of slow feeds out of the headlines
+	headlineMaxAge = 48 * time.Hour
+	// maxFeedSize is how much of a feed is read at most
+	maxFeedSize = 10 << 20
+)
+
+type Service struct {
+	HTTPClient *http.Client
+	Feeds      map[util.Category][]string
+
+	mu    sync.Mutex
+	cache map[string]*feedState
+}
+
+// feedState is what is kept of a feed between requests, so it can be
+// requested conditionally and its last items reused when it hasn't changed.
+type feedState struct {
+	etag         string
+	lastModified string
+	articles     []eventfeed.Article
+}
+
+func NewRSSService(client *http.Client, feeds map[util.Category][]string) *Service {
+	return &Service{
+		HTTPClient: client,
+		Feeds:      feeds,
+		cache:      map[string]*feedState{},
+	}
+}
+
+func (s *Service) Name() string {
+	return feedName
+}
+
+// FetchTopHeadlines returns the newest items of the feeds configured for the
+// category.
+func (s *Service) FetchTopHeadlines(ctx context.Context, category eventfeed.Category) ([]eventfeed.Article, error) {
+	urls := s.Feeds[feedCategory(category)]
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	articles, err := s.fetchFeeds(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-headlineMaxAge)
+	var headlines []eventfeed.Article
+	for _, article := range articles {
+		if published := article.Published(); published.IsZero() || published.After(cutoff) {
+			headlines = append(headlines, article)
+		}
+	}
+
+	sort.SliceStable(headlines, func(i, j int) bool {
+		return headlines[i].Published().After(headlines[j].Published())
+	})
+
+	return limit(headlines, headlineRecords), nil
+}
+
+// FetchWithKeywords searches the current items of every configured feed.
+// Items matching more keywords, in the title above all, rank first.
+func (s *Service) FetchWithKeywords(ctx context.Context, keywords []string) ([]eventfeed.Article, error) {
+	patterns := keywordPatterns(keywords)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no keywords provided")
+	}
+
+	articles, err := s.fetchFeeds(ctx, s.allFeeds())
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		article eventfeed.Article
+		score   int
+	}
+	var matches []scored
+	for _, article := range articles {
+		score := 0
+		for _, pattern := range patterns {
+			if pattern.MatchString(article.Title) {
+				score += 2
+			} else if pattern.MatchString(article.Description) || pattern.MatchString(article.Content) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{article: article, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].article.Published().After(matches[j].article.Published())
+	})
+
+	result := make([]eventfeed.Article, len(matches))
+	for i, m := range matches {
+		result[i] = m.article
+	}
+
+	return limit(result, searchRecords), nil
+}
+
+// fetchFeeds fetches feeds concurrently. It fails only when every feed
+// fails; the errors of the others are logged.
+func (s *Service) fetchFeeds(ctx context.Context, urls []string) ([]eventfeed.Article, error) {
+	results := make([][]eventfeed.Article, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			results[i], errs[i] = s.Fetch(ctx, url)
+		}(i, url)
+	}
+	wg.Wait()
+
+	var articles []eventfeed.Article
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Error fetching feed %s: %v", urls[i], err)
+			failed++
+			continue
+		}
+		articles = append(articles, results[i]...)
+	}
+
+	if failed == len(urls) {
+		return nil, fmt.Errorf("all %d feeds failed, the last with: %v", failed, errs[len(errs)-1])
+	}
+
+	return articles, nil
+}
+
+// Fetch requests a feed conditionally, with the ETag and Last-Modified of the
+// previous response, and returns the previous items when it hasn't changed.
+func (s *Service) Fetch(ctx context.Context, url string) ([]eventfeed.Article, error) {
+	s.mu.Lock()
+	state := s.cache[url]
+	s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8")
+	if state != nil {
+		if state.etag != "" {
+			req.Header.Set("If-None-Match", state.etag)
+		}
+		if state.lastModified != "" {
+			req.Header.Set("If-Modified-Since", state.lastModified)
+		}
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && state != nil {
+		return state.articles, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return nil, fmt.Errorf("feed returned status: %s, but the error message could not be read", resp.Status)
+		}
+
+		return nil, fmt.Errorf("feed returned status: %s, message: %s", resp.Status, string(respBody))
+	}
+
+	decoder := xml.NewDecoder(io.LimitReader(resp.Body, maxFeedSize))
+	// Feeds are often not quite valid XML, e.g. with HTML entities
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+
+	var document Document
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("error parsing feed: %v", err)
+	}
+
+	switch document.XMLName.Local {
+	case "rss", "feed", "RDF":
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed: <%s>", document.XMLName.Local)
+	}
+
+	articles := document.Articles()
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	s.mu.Lock()
+	if etag != "" || lastModified != "" {
+		s.cache[url] = &feedState{etag: etag, lastModified: lastModified, articles: articles}
+	} else {
+		delete(s.cache, url)
+	}
+	s.mu.Unlock()
+
+	return articles, nil
+}
+
+// feedCategory maps a headline category to the category its feeds are
+// configured under. Every category maps to one, so no category is left
+// without headlines.
+func feedCategory(category eventfeed.Category) util.Category {
+	switch category {
+	case eventfeed.Business:
+		return util.Economy
+	case eventfeed.Technology, eventfeed.Science:
+		return util.Technology
+	case eventfeed.Entertainment, eventfeed.Sports:
+		return util.Culture
+	default:
+		return util.Politics
+	}
+}
+
+// allFeeds lists every configured feed once.
+func (s *Service) allFeeds() []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, feeds := range s.Feeds {
+		for _, url := range feeds {
+			if !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// keywordPatterns match the keywords as whole words, ignoring case, so "AI"
+// doesn't match "said". A word boundary is only required on a side that ends
+// in a word character, so keywords such as "U.S." and "C++" still match.
+func keywordPatterns(keywords []string) []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(keyword)
+		if isWordChar(keyword[0]) {
+			pattern = `\b` + pattern
+		}
+		if isWordChar(keyword[len(keyword)-1]) {
+			pattern += `\b`
+		}
+		patterns = append(patterns, regexp.MustCompile(`(?i)`+pattern))
+	}
+	return patterns
+}
+
+// isWordChar reports whether c is a word character as \b sees it.
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func limit(articles []eventfeed.Article, n int) []eventfeed.Article {
+	if len(articles) > n {
+		return articles[:n]
+	}
+	return articles
+}
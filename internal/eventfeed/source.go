@@ -0,0 +1,48 @@
+package eventfeed
+
+import (
+	"context"
+	"fmt"
+)
+
+// Source is a news feed the pipeline takes headlines and related articles
+// from. Every source returns normalized articles, so the pipeline can mix
+// them freely.
+type Source interface {
+	// Name identifies the feed, and is set as Feed on its articles
+	Name() string
+	// FetchTopHeadlines returns the current top articles of a category. A
+	// source without articles for the category returns none rather than an
+	// error.
+	FetchTopHeadlines(ctx context.Context, category Category) ([]Article, error)
+	// FetchWithKeywords returns recent articles matching the keywords, best
+	// match first.
+	FetchWithKeywords(ctx context.Context, keywords []string) ([]Article, error)
+}
+
+// EventSource is a prediction market feed the pipeline takes events from.
+type EventSource interface {
+	Name() string
+	FetchTopEvents(ctx context.Context) ([]Event, error)
+}
+
+type Category string
+
+const (
+	Business      Category = "business"
+	Entertainment Category = "entertainment"
+	General       Category = "general"
+	Health        Category = "health"
+	Science       Category = "science"
+	Sports        Category = "sports"
+	Technology    Category = "technology"
+)
+
+func ValidateCategory(category string) (Category, error) {
+	switch Category(category) {
+	case Business, Entertainment, General, Health, Science, Sports, Technology:
+		return Category(category), nil
+	default:
+		return "", fmt.Errorf("invalid category: %s", category)
+	}
+}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"log"
+	"strings"
+)
+
+// CharsPerToken is a rough average for English text, used wherever token
+// counts have to be estimated.
+const CharsPerToken = 4
+
+// DefaultContextWindow is assumed for models that aren't known and have no
+// context_window configured.
+const DefaultContextWindow = 8192
+
+// contextWindows are the context sizes of common models, in tokens, matched
+// by name prefix with the longest prefix winning.
+var contextWindows = map[string]int{
+	"gpt-4o":                 128000,
+	"gpt-4.1":                1000000,
+	"gpt-4-turbo":            128000,
+	"gpt-4":                  8192,
+	"gpt-3.5-turbo":          16385,
+	"o1":                     200000,
+	"o3":                     200000,
+	"o4-mini":                200000,
+	"meta/meta-llama-3-":     8192,
+	"meta/meta-llama-3.1-":   128000,
+	"meta/llama-4-":          128000,
+	"mistralai/mixtral-8x7b": 32768,
+	"llama3:":                8192,
+	"llama3.1":               128000,
+	"llama3.2":               128000,
+	"llama3.3":               128000,
+	"mistral":                32768,
+	"qwen2.5":                32768,
+	"gemma2":                 8192,
+	"deepseek-r1":            128000,
+}
+
+// EstimateTokens approximates the token count of text.
+func EstimateTokens(text string) int {
+	return (len(text) + CharsPerToken - 1) / CharsPerToken
+}
+
+// ContextWindowFor returns the context size of a model by name.
+func ContextWindowFor(model string) int {
+	model = strings.ToLower(model)
+
+	best, window := 0, DefaultContextWindow
+	for prefix, size := range contextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, window = len(prefix), size
+		}
+	}
+
+	return window
+}
+
+// PromptBudget is implemented by providers that know how many prompt tokens
+// fit next to the output of a template type.
+type PromptBudget interface {
+	PromptBudget(templateType promptgen.TemplateType) int
+}
+
+// promptBudget is the number of prompt tokens provider accepts for
+// templateType.
+func promptBudget(provider Provider, templateType promptgen.TemplateType) int {
+	if b, ok := provider.(PromptBudget); ok {
+		return b.PromptBudget(templateType)
+	}
+	return ContextWindowFor(provider.ModelName()) - DefaultMaxTokens(templateType)
+}
+
+// budgetShare leaves room for the error in EstimateTokens.
+const budgetShare = 0.9
+
+// trimmedContentChars is how much article content is kept once the full
+// content doesn't fit.
+const trimmedContentChars = 600
+
+// renderFunc renders the forecast prompt for a main article and the related
+// articles it may cite.
+type renderFunc func(mainArticle eventfeed.Article, relatedArticles []eventfeed.Article) (string, error)
+
+// fitPrompt renders the prompt within budget tokens. Until it fits, it trims
+// the content of the related articles, then keeps only their descriptions as
+// a summary, then drops related articles from the end so the best ranked stay,
+// and finally trims the content of the main article. The related articles it
+// returns are a prefix of relatedArticles, so outcome sources keep their
+// indexes.
+func fitPrompt(budget int, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, render renderFunc) (string, []eventfeed.Article, error) {
+	limit := int(float64(budget) * budgetShare)
+
+	prompt, err := render(mainArticle, relatedArticles)
+	if err != nil || EstimateTokens(prompt) <= limit {
+		return prompt, relatedArticles, err
+	}
+	fullTokens := EstimateTokens(prompt)
+
+	fit := func(stage string, main eventfeed.Article, related []eventfeed.Article) (string, bool, error) {
+		prompt, err := render(main, related)
+		if err != nil {
+			return "", false, err
+		}
+		if EstimateTokens(prompt) > limit {
+			return "", false, nil
+		}
+
+		log.Printf("Forecast prompt of about %d tokens is over the budget of %d, %s and kept %d of %d related articles",
+			fullTokens, limit, stage, len(related), len(relatedArticles))
+		return prompt, true, nil
+	}
+
+	trimmed := withContent(relatedArticles, trimmedContentChars)
+	if prompt, ok, err := fit("trimmed their content", mainArticle, trimmed); ok || err != nil {
+		return prompt, trimmed, err
+	}
+
+	summarized := withContent(relatedArticles, 0)
+	if prompt, ok, err := fit("summarized them by their description", mainArticle, summarized); ok || err != nil {
+		return prompt, summarized, err
+	}
+
+	for n := len(summarized) - 1; n >= 0; n-- {
+		if prompt, ok, err := fit("dropped the lowest ranked", mainArticle, summarized[:n]); ok || err != nil {
+			return prompt, summarized[:n], err
+		}
+	}
+
+	mainArticle.Content = trimContent(mainArticle.Content, trimmedContentChars)
+	if prompt, ok, err := fit("trimmed the main article", mainArticle, nil); ok || err != nil {
+		return prompt, nil, err
+	}
+
+	return "", nil, fmt.Errorf("forecast prompt doesn't fit the budget of %d tokens even without related articles", limit)
+}
+
+// withContent copies articles with their content cut to maxChars.
+func withContent(articles []eventfeed.Article, maxChars int) []eventfeed.Article {
+	result := make([]eventfeed.Article, len(articles))
+	for i, article := range articles {
+		article.Content = trimContent(article.Content, maxChars)
+		result[i] = article
+	}
+	return result
+}
+
+func trimContent(content string, maxChars int) string {
+	runes := []rune(content)
+	if len(runes) <= maxChars {
+		return content
+	}
+	if maxChars == 0 {
+		return ""
+	}
+	return strings.TrimSpace(string(runes[:maxChars])) + "..."
+}
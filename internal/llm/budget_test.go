@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"strings"
+	"testing"
+)
+
+// renderArticles renders the main article and every related one with their
+// content, CharsPerToken characters per token.
+func renderArticles(mainArticle eventfeed.Article, relatedArticles []eventfeed.Article) (string, error) {
+	var b strings.Builder
+	b.WriteString(mainArticle.Description + mainArticle.Content)
+	for _, article := range relatedArticles {
+		b.WriteString(article.Description + article.Content)
+	}
+	return b.String(), nil
+}
+
+func TestFitPrompt(t *testing.T) {
+	tokens := func(n int) string {
+		return strings.Repeat("x", n*CharsPerToken)
+	}
+
+	mainArticle := eventfeed.Article{Description: tokens(10), Content: tokens(1000)}
+	related := []eventfeed.Article{
+		{Description: tokens(10), Content: tokens(1000)},
+		{Description: tokens(10), Content: tokens(1000)},
+		{Description: tokens(10), Content: tokens(1000)},
+	}
+	// The content of a trimmed article is about 150 tokens
+	trimmed := EstimateTokens(trimContent(tokens(1000), trimmedContentChars))
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantRelated int
+		wantContent int
+		wantMain    int
+		wantErr     bool
+	}{
+		{"fits", 10000, 3, 1000, 1000, false},
+		{"trims related content", 2000, 3, trimmed, 1000, false},
+		{"summarizes related", 1200, 3, 0, 1000, false},
+		{"drops the lowest ranked", 1150, 2, 0, 1000, false},
+		{"trims the main article", 500, 0, 0, trimmed, false},
+		{"doesn't fit", 10, 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, got, err := fitPrompt(tt.budget, mainArticle, related, renderArticles)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got a prompt of %d tokens", EstimateTokens(prompt))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if limit := int(float64(tt.budget) * budgetShare); EstimateTokens(prompt) > limit {
+				t.Errorf("prompt of %d tokens is over the limit of %d", EstimateTokens(prompt), limit)
+			}
+			if len(got) != tt.wantRelated {
+				t.Fatalf("kept %d related articles, want %d", len(got), tt.wantRelated)
+			}
+			for i, article := range got {
+				if article.Description != related[i].Description {
+					t.Errorf("related article %d is out of order", i)
+				}
+				if n := EstimateTokens(article.Content); n != tt.wantContent {
+					t.Errorf("related article %d has %d tokens of content, want %d", i, n, tt.wantContent)
+				}
+			}
+
+			wantPrompt := EstimateTokens(mainArticle.Description) + tt.wantMain + len(got)*EstimateTokens(related[0].Description) + len(got)*tt.wantContent
+			if n := EstimateTokens(prompt); n != wantPrompt {
+				t.Errorf("prompt has %d tokens, want %d", n, wantPrompt)
+			}
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/db/model"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FileStore keeps one JSON file per entry under
+// <Dir>/<template type>/<model>/<prompt hash>.json.
+type FileStore struct {
+	Dir string
+}
+
+var _ Store = &FileStore{}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) GetCacheEntry(templateType, modelName, promptHash string) (*model.LLMCacheEntry, error) {
+	b, err := os.ReadFile(s.path(templateType, modelName, promptHash))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entry model.LLMCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, fmt.Errorf("error parsing cache entry: %v", err)
+	}
+
+	return &entry, nil
+}
+
+func (s *FileStore) SaveCacheEntry(entry *model.LLMCacheEntry) error {
+	path := s.path(entry.TemplateType, entry.Model, entry.PromptHash)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crash never leaves a half-written entry
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *FileStore) path(templateType, modelName, promptHash string) string {
+	return filepath.Join(s.Dir,
+		unsafePathChars.ReplaceAllString(templateType, "_"),
+		unsafePathChars.ReplaceAllString(modelName, "_"),
+		unsafePathChars.ReplaceAllString(promptHash, "_")+".json")
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"time"
+)
+
+// Store persists model outputs. GetCacheEntry returns nil when nothing is stored.
+type Store interface {
+	GetCacheEntry(templateType, modelName, promptHash string) (*model.LLMCacheEntry, error)
+	SaveCacheEntry(entry *model.LLMCacheEntry) error
+}
+
+// Provider serves repeated prompts from a Store instead of the wrapped
+// provider. Entries are keyed by template type, model and a hash of the
+// rendered prompt and expire after TTL; a zero TTL never expires. An output
+// is only stored once the caller accepts it, see llm.Response.Accepted, so
+// answers that fail validation are asked again rather than replayed.
+type Provider struct {
+	Next  llm.Provider
+	Store Store
+	TTL   time.Duration
+}
+
+var _ llm.Provider = &Provider{}
+
+func NewCachedProvider(next llm.Provider, store Store, ttl time.Duration) *Provider {
+	return &Provider{
+		Next:  next,
+		Store: store,
+		TTL:   ttl,
+	}
+}
+
+func (p *Provider) ModelName() string {
+	return p.Next.ModelName()
+}
+
+func (p *Provider) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	templateType := string(req.TemplateType)
+	modelName := llm.ModelFor(p.Next, req)
+	promptHash := HashPrompt(req)
+
+	entry, err := p.Store.GetCacheEntry(templateType, modelName, promptHash)
+	if err != nil {
+		// A broken cache must never stop generation
+		log.Printf("Error reading LLM cache: %v", err)
+	} else if entry != nil && !p.expired(entry) {
+		return &llm.Response{Output: entry.Output}, nil
+	}
+
+	resp, err := p.Next.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	accept := resp.Accept
+	output := resp.Output
+	resp.Accept = func() {
+		if accept != nil {
+			accept()
+		}
+
+		err := p.Store.SaveCacheEntry(&model.LLMCacheEntry{
+			TemplateType: templateType,
+			Model:        modelName,
+			PromptHash:   promptHash,
+			Output:       output,
+			CreatedAt:    time.Now().UTC(),
+		})
+		if err != nil {
+			log.Printf("Error writing LLM cache: %v", err)
+		}
+	}
+
+	return resp, nil
+}
+
+func (p *Provider) expired(entry *model.LLMCacheEntry) bool {
+	return p.TTL > 0 && time.Since(entry.CreatedAt) > p.TTL
+}
+
+// HashPrompt hashes everything besides the template type and model that
+// changes the model's answer.
+func HashPrompt(req llm.Request) string {
+	temperature := "default"
+	if req.Temperature != nil {
+		temperature = fmt.Sprintf("%g", *req.Temperature)
+	}
+	key := fmt.Sprintf("%d\x00%t\x00%s\x00%s", req.MaxTokens, req.JSON, temperature, req.Prompt)
+	if req.Sample > 0 {
+		// Keep the hash of unsampled requests stable for existing entries
+		key = fmt.Sprintf("%s\x00%d", key, req.Sample)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/llm"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps entries in a map.
+type memoryStore map[string]*model.LLMCacheEntry
+
+func (s memoryStore) GetCacheEntry(templateType, modelName, promptHash string) (*model.LLMCacheEntry, error) {
+	return s[templateType+"/"+modelName+"/"+promptHash], nil
+}
+
+func (s memoryStore) SaveCacheEntry(entry *model.LLMCacheEntry) error {
+	s[entry.TemplateType+"/"+entry.Model+"/"+entry.PromptHash] = entry
+	return nil
+}
+
+// countingProvider answers every prompt with the number of calls so far and
+// counts how often its answers are accepted.
+type countingProvider struct {
+	calls    int
+	accepted int
+}
+
+func (p *countingProvider) Complete(context.Context, llm.Request) (*llm.Response, error) {
+	p.calls++
+	return &llm.Response{
+		Output: string(rune('0' + p.calls)),
+		Accept: func() { p.accepted++ },
+	}, nil
+}
+
+func (p *countingProvider) ModelName() string {
+	return "counting"
+}
+
+func TestCacheStoresAcceptedOutputOnly(t *testing.T) {
+	next := &countingProvider{}
+	store := memoryStore{}
+	p := NewCachedProvider(next, store, 0)
+	req := llm.Request{TemplateType: "extract_keywords", Prompt: "prompt"}
+
+	// A rejected answer is not replayed
+	resp, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Output != "1" || len(store) != 0 {
+		t.Fatalf("output %q with %d stored entries, want 1 and none", resp.Output, len(store))
+	}
+
+	resp, err = p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Output != "2" {
+		t.Fatalf("output %q, want a new answer", resp.Output)
+	}
+	resp.Accepted()
+	if len(store) != 1 || next.accepted != 1 {
+		t.Fatalf("%d stored entries and %d accepted answers after accepting, want 1 and 1", len(store), next.accepted)
+	}
+
+	// The accepted answer is replayed
+	resp, err = p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Output != "2" || next.calls != 2 {
+		t.Errorf("output %q after %d calls, want the cached 2 after 2", resp.Output, next.calls)
+	}
+	resp.Accepted()
+}
+
+func TestCacheExpiry(t *testing.T) {
+	next := &countingProvider{}
+	store := memoryStore{}
+	p := NewCachedProvider(next, store, time.Hour)
+	req := llm.Request{TemplateType: "extract_keywords", Prompt: "prompt"}
+
+	resp, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Accepted()
+	for _, entry := range store {
+		entry.CreatedAt = time.Now().Add(-2 * time.Hour)
+	}
+
+	resp, err = p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Output != "2" {
+		t.Errorf("output %q, want a new answer for an expired entry", resp.Output)
+	}
+}
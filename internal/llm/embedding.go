@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"context"
+	"math"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// close the texts are in meaning.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// ModelName identifies the embedding model. Vectors of different models
+	// can't be compared.
+	ModelName() string
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// their lengths differ or either is all zeros.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,104 @@
+package ensemble
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"math"
+	"sort"
+)
+
+type Aggregate string
+
+const (
+	Mean   Aggregate = "mean"
+	Median Aggregate = "median"
+)
+
+func ParseAggregate(s string) (Aggregate, error) {
+	switch Aggregate(s) {
+	case "", Median:
+		return Median, nil
+	case Mean:
+		return Mean, nil
+	default:
+		return "", fmt.Errorf("invalid ensemble aggregate: %s", s)
+	}
+}
+
+// Cluster groups the outcomes different sources proposed for the same
+// prediction. Each source contributes at most once.
+type Cluster struct {
+	// Outcome is the first outcome in the cluster. Its wording, rationale
+	// and sources represent the cluster.
+	Outcome       dto.Outcome
+	Contributions []dto.Contribution
+}
+
+// ClusterOutcomes aligns the outcomes of each forecast, joining an outcome to
+// the cluster it is most similar to from threshold on. sources names the
+// forecast at the same index. Earlier sources take precedence, so their
+// outcome represents the cluster.
+func ClusterOutcomes(sources []string, forecasts []*dto.Forecast, similarity func(a, b string) float64, threshold float64) []*Cluster {
+	var clusters []*Cluster
+
+	for i, f := range forecasts {
+		// Clusters this source already joined, so two of its own outcomes
+		// are never merged
+		joined := map[*Cluster]bool{}
+
+		for _, o := range f.Outcomes {
+			var best *Cluster
+			bestScore := threshold
+
+			for _, c := range clusters {
+				if joined[c] {
+					continue
+				}
+				if score := similarity(c.Content(), o.Content); score >= bestScore {
+					best, bestScore = c, score
+				}
+			}
+
+			if best == nil {
+				best = &Cluster{Outcome: o}
+				clusters = append(clusters, best)
+			}
+
+			best.Contributions = append(best.Contributions, dto.Contribution{
+				Model:           sources[i],
+				Content:         o.Content,
+				ConfidenceLevel: o.ConfidenceLevel,
+			})
+			joined[best] = true
+		}
+	}
+
+	return clusters
+}
+
+func (c *Cluster) Content() string {
+	return c.Outcome.Content
+}
+
+// Confidence aggregates the confidence levels of the contributing sources.
+func (c *Cluster) Confidence(aggregate Aggregate) int {
+	levels := make([]float64, len(c.Contributions))
+	for i, contribution := range c.Contributions {
+		levels[i] = float64(contribution.ConfidenceLevel)
+	}
+
+	if aggregate == Mean {
+		var sum float64
+		for _, l := range levels {
+			sum += l
+		}
+		return int(math.Round(sum / float64(len(levels))))
+	}
+
+	sort.Float64s(levels)
+	mid := len(levels) / 2
+	if len(levels)%2 == 0 {
+		return int(math.Round((levels[mid-1] + levels[mid]) / 2))
+	}
+	return int(levels[mid])
+}
@@ -0,0 +1,113 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultSampleTemperature is used when sampling is on but no temperature is
+// configured. At zero every sample would be the same.
+const DefaultSampleTemperature = 0.7
+
+// Sampler generates a forecast several times and keeps the outcomes that come
+// up most often (self-consistency). The Forecaster should run at a non-zero
+// temperature.
+type Sampler struct {
+	Forecaster Forecaster
+	Samples    int
+	Matcher    Matcher
+}
+
+func NewSampler(forecaster Forecaster, samples int, matcher Matcher) *Sampler {
+	return &Sampler{
+		Forecaster: forecaster,
+		Samples:    samples,
+		Matcher:    matcher,
+	}
+}
+
+func (s *Sampler) GetForecast(ctx context.Context, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error) {
+	results := make([]*dto.Forecast, s.Samples)
+	errs := make([]error, s.Samples)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.Samples; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = s.Forecaster.GetForecast(llm.WithSample(ctx, i+1), mainArticle, relatedArticles, event)
+		}(i)
+	}
+	wg.Wait()
+
+	var sources []string
+	var forecasts []*dto.Forecast
+	for i, f := range results {
+		if errs[i] != nil {
+			log.Printf("Forecast sample %d failed: %v", i+1, errs[i])
+			continue
+		}
+		sources = append(sources, fmt.Sprintf("sample %d", i+1))
+		forecasts = append(forecasts, f)
+	}
+
+	if len(forecasts) == 0 {
+		return nil, fmt.Errorf("every forecast sample failed: %v", errors.Join(errs...))
+	}
+
+	return s.merge(ctx, sources, forecasts), nil
+}
+
+// merge ranks the outcome clusters by Consistency and keeps as many as the
+// first sample has.
+func (s *Sampler) merge(ctx context.Context, sources []string, forecasts []*dto.Forecast) *dto.Forecast {
+	primary := forecasts[0]
+
+	clusters := s.Matcher.Cluster(ctx, sources, forecasts)
+
+	confidence := make(map[*Cluster]int, len(clusters))
+	for _, c := range clusters {
+		confidence[c] = Consistency(c, len(forecasts))
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return confidence[clusters[i]] > confidence[clusters[j]]
+	})
+
+	if len(clusters) > len(primary.Outcomes) {
+		clusters = clusters[:len(primary.Outcomes)]
+	}
+
+	outcomes := make([]dto.Outcome, len(clusters))
+	for i, c := range clusters {
+		outcomes[i] = dto.Outcome{
+			Content:         c.Content(),
+			Rationale:       c.Outcome.Rationale,
+			SourceIndexes:   c.Outcome.SourceIndexes,
+			ConfidenceLevel: confidence[c],
+		}
+	}
+
+	merged := *primary
+	merged.Outcomes = outcomes
+
+	return &merged
+}
+
+// Consistency weighs how often an outcome was sampled equally with the mean
+// confidence the samples that produced it stated. An outcome every sample
+// proposes at 80% scores 90; one proposed by a single sample out of five at
+// 80% scores 50.
+func Consistency(c *Cluster, samples int) int {
+	frequency := float64(len(c.Contributions)) / float64(samples) * 100
+	stated := float64(c.Confidence(Mean))
+
+	return int(math.Round((frequency + stated) / 2))
+}
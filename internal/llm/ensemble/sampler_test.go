@@ -0,0 +1,141 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/llm"
+	"reflect"
+	"testing"
+)
+
+// sampledForecaster answers each sample with the forecast or error of its
+// number.
+type sampledForecaster struct {
+	forecasts map[int]*dto.Forecast
+	errs      map[int]error
+}
+
+func (f sampledForecaster) GetForecast(ctx context.Context, _ eventfeed.Article, _ []eventfeed.Article, _ *eventfeed.Event) (*dto.Forecast, error) {
+	n := llm.SampleFromContext(ctx)
+	return f.forecasts[n], f.errs[n]
+}
+
+func outcomes(contents []string, levels []int) []dto.Outcome {
+	o := make([]dto.Outcome, len(contents))
+	for i := range contents {
+		o[i] = dto.Outcome{Content: contents[i], ConfidenceLevel: levels[i]}
+	}
+	return o
+}
+
+func TestSampler(t *testing.T) {
+	forecaster := sampledForecaster{
+		forecasts: map[int]*dto.Forecast{
+			1: {Headline: "Fed decision", Outcomes: outcomes([]string{"Fed cuts rates in September", "Fed holds rates"}, []int{80, 20})},
+			2: {Headline: "Fed meeting", Outcomes: outcomes([]string{"Fed cuts rates September", "Inflation rises"}, []int{60, 40})},
+			3: {Headline: "Fed outlook", Outcomes: outcomes([]string{"Fed cuts rates in September", "Fed holds rates"}, []int{70, 30})},
+		},
+		errs: map[int]error{4: errors.New("invalid JSON")},
+	}
+
+	s := NewSampler(forecaster, 4, NewMatcher(nil))
+	got, err := s.GetForecast(context.Background(), eventfeed.Article{}, nil, nil)
+	if err != nil {
+		t.Fatalf("GetForecast() error: %v", err)
+	}
+
+	if got.Headline != "Fed decision" {
+		t.Errorf("Headline = %q, want the first sample's", got.Headline)
+	}
+
+	// Of 3 answering samples: the cut is in all 3 at a mean of 70, the hold
+	// in 2 at 25 and inflation in 1 at 40. Only as many as the first sample
+	// has are kept.
+	want := outcomes([]string{"Fed cuts rates in September", "Fed holds rates"}, []int{85, 46})
+	if !reflect.DeepEqual(got.Outcomes, want) {
+		t.Errorf("Outcomes = %+v, want %+v", got.Outcomes, want)
+	}
+
+	failing := NewSampler(sampledForecaster{errs: map[int]error{1: errors.New("down"), 2: errors.New("down")}}, 2, NewMatcher(nil))
+	if _, err := failing.GetForecast(context.Background(), eventfeed.Article{}, nil, nil); err == nil {
+		t.Error("GetForecast() returned no error when every sample failed")
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		levels  []int
+		samples int
+		want    int
+	}{
+		{"every sample", []int{80, 80, 80, 80, 80}, 5, 90},
+		{"one sample of five", []int{80}, 5, 50},
+		{"mean of stated", []int{90, 60}, 4, 63},
+		{"rounded", []int{25}, 3, 29},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cluster{}
+			for _, level := range tt.levels {
+				c.Contributions = append(c.Contributions, dto.Contribution{ConfidenceLevel: level})
+			}
+			if got := Consistency(c, tt.samples); got != tt.want {
+				t.Errorf("Consistency() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterConfidence(t *testing.T) {
+	tests := []struct {
+		levels     []int
+		wantMean   int
+		wantMedian int
+	}{
+		{[]int{60}, 60, 60},
+		{[]int{10, 60, 80}, 50, 60},
+		{[]int{10, 60, 80, 85}, 59, 70},
+	}
+
+	for _, tt := range tests {
+		c := &Cluster{}
+		for _, level := range tt.levels {
+			c.Contributions = append(c.Contributions, dto.Contribution{ConfidenceLevel: level})
+		}
+		if got := c.Confidence(Mean); got != tt.wantMean {
+			t.Errorf("mean of %v = %d, want %d", tt.levels, got, tt.wantMean)
+		}
+		if got := c.Confidence(Median); got != tt.wantMedian {
+			t.Errorf("median of %v = %d, want %d", tt.levels, got, tt.wantMedian)
+		}
+	}
+}
+
+func TestClusterOutcomes(t *testing.T) {
+	clusters := ClusterOutcomes([]string{"a", "b"}, []*dto.Forecast{
+		{Outcomes: outcomes([]string{"Fed cuts rates", "Fed holds rates"}, []int{70, 30})},
+		{Outcomes: outcomes([]string{"The Fed cuts rates.", "Fed cuts rates again"}, []int{60, 20})},
+	}, Similarity, DefaultThreshold)
+
+	var got [][]string
+	for _, c := range clusters {
+		var contents []string
+		for _, contribution := range c.Contributions {
+			contents = append(contents, contribution.Model+": "+contribution.Content)
+		}
+		got = append(got, contents)
+	}
+
+	// b's second outcome is close to the cut too, but b already joined it
+	want := [][]string{
+		{"a: Fed cuts rates", "b: The Fed cuts rates."},
+		{"a: Fed holds rates", "b: Fed cuts rates again"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Forecaster is the part of llm.Service the ensemble and the sampler build on,
+// so that they can be combined.
+type Forecaster interface {
+	GetForecast(ctx context.Context, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error)
+}
+
+var (
+	_ Forecaster = llm.Service(nil)
+	_ Forecaster = &Service{}
+	_ Forecaster = &Sampler{}
+)
+
+// Member is one model taking part in the ensemble.
+type Member struct {
+	Name    string
+	Service Forecaster
+}
+
+// Service generates a forecast with every member and merges the results.
+type Service struct {
+	Members   []Member
+	Aggregate Aggregate
+	Matcher   Matcher
+}
+
+func NewEnsembleService(members []Member, aggregate Aggregate, matcher Matcher) *Service {
+	return &Service{
+		Members:   members,
+		Aggregate: aggregate,
+		Matcher:   matcher,
+	}
+}
+
+func (s *Service) GetForecast(ctx context.Context, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error) {
+	results := make([]*dto.Forecast, len(s.Members))
+	errs := make([]error, len(s.Members))
+
+	var wg sync.WaitGroup
+	for i, m := range s.Members {
+		wg.Add(1)
+		go func(i int, m Member) {
+			defer wg.Done()
+			results[i], errs[i] = m.Service.GetForecast(ctx, mainArticle, relatedArticles, event)
+		}(i, m)
+	}
+	wg.Wait()
+
+	var sources []string
+	var forecasts []*dto.Forecast
+	for i, m := range s.Members {
+		if errs[i] != nil {
+			log.Printf("Ensemble member %s failed: %v", m.Name, errs[i])
+			errs[i] = fmt.Errorf("%s: %v", m.Name, errs[i])
+			continue
+		}
+		sources = append(sources, m.Name)
+		forecasts = append(forecasts, results[i])
+	}
+
+	if len(forecasts) == 0 {
+		return nil, fmt.Errorf("every ensemble member failed: %v", errors.Join(errs...))
+	}
+
+	return s.merge(ctx, sources, forecasts), nil
+}
+
+// merge keeps the outcomes a majority of the members agree on, ranked by how
+// many members proposed them, and as many as the first forecast has.
+func (s *Service) merge(ctx context.Context, sources []string, forecasts []*dto.Forecast) *dto.Forecast {
+	primary := forecasts[0]
+
+	clusters := s.Matcher.Cluster(ctx, sources, forecasts)
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Contributions) > len(clusters[j].Contributions)
+	})
+
+	quorum := (len(forecasts) + 1) / 2
+
+	var agreed []*Cluster
+	for _, c := range clusters {
+		if len(c.Contributions) >= quorum {
+			agreed = append(agreed, c)
+		}
+	}
+	if len(agreed) == 0 {
+		// The members agree on nothing, so fall back to the best of the rest
+		agreed = clusters
+	}
+	if len(agreed) > len(primary.Outcomes) {
+		agreed = agreed[:len(primary.Outcomes)]
+	}
+
+	outcomes := make([]dto.Outcome, len(agreed))
+	for i, c := range agreed {
+		outcomes[i] = dto.Outcome{
+			Content:         c.Content(),
+			Rationale:       c.Outcome.Rationale,
+			SourceIndexes:   c.Outcome.SourceIndexes,
+			ConfidenceLevel: c.Confidence(s.Aggregate),
+			Contributions:   c.Contributions,
+		}
+	}
+
+	merged := *primary
+	merged.Outcomes = outcomes
+
+	if primary.Provenance != nil {
+		provenance := *primary.Provenance
+		provenance.Model = strings.Join(sources, ", ")
+		merged.Provenance = &provenance
+	}
+
+	return &merged
+}
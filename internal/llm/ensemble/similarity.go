@@ -0,0 +1,125 @@
+package ensemble
+
+import (
+	"context"
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultThreshold is the word similarity above which two outcomes are
+	// treated as the same prediction worded differently.
+	DefaultThreshold = 0.5
+	// DefaultSemanticThreshold is the same for the similarity of their
+	// embeddings, which is high even for unrelated sentences on one topic.
+	DefaultSemanticThreshold = 0.85
+)
+
+// Matcher decides which outcomes are the same prediction worded differently.
+// It compares their meaning with the Embedder, and falls back to comparing
+// their words with Similarity when there is none or embedding fails.
+type Matcher struct {
+	Embedder          llm.Embedder
+	SemanticThreshold float64
+	Threshold         float64
+}
+
+func NewMatcher(embedder llm.Embedder) Matcher {
+	return Matcher{
+		Embedder:          embedder,
+		SemanticThreshold: DefaultSemanticThreshold,
+		Threshold:         DefaultThreshold,
+	}
+}
+
+// Cluster aligns the outcomes of the forecasts, see ClusterOutcomes.
+func (m Matcher) Cluster(ctx context.Context, sources []string, forecasts []*dto.Forecast) []*Cluster {
+	seen := map[string]bool{}
+	var texts []string
+	for _, f := range forecasts {
+		for _, o := range f.Outcomes {
+			if !seen[o.Content] {
+				seen[o.Content] = true
+				texts = append(texts, o.Content)
+			}
+		}
+	}
+
+	similarity, threshold := m.similarity(ctx, texts)
+	return ClusterOutcomes(sources, forecasts, similarity, threshold)
+}
+
+// similarity returns a function scoring how similar two of texts are, and the
+// score from which they count as the same.
+func (m Matcher) similarity(ctx context.Context, texts []string) (func(a, b string) float64, float64) {
+	if m.Embedder == nil {
+		return Similarity, m.Threshold
+	}
+
+	vectors, err := m.Embedder.Embed(ctx, texts)
+	if err == nil && len(vectors) != len(texts) {
+		err = fmt.Errorf("got %d embeddings for %d outcomes", len(vectors), len(texts))
+	}
+	if err != nil {
+		log.Printf("Comparing outcomes by their words, embedding them failed: %v", err)
+		return Similarity, m.Threshold
+	}
+
+	embeddings := make(map[string][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[text] = vectors[i]
+	}
+
+	return func(a, b string) float64 {
+		return llm.CosineSimilarity(embeddings[a], embeddings[b])
+	}, m.SemanticThreshold
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "their": true, "to": true,
+	"was": true, "were": true, "will": true, "with": true, "within": true,
+}
+
+// Similarity is the lexical fallback of Matcher: the cosine similarity of the
+// word counts of a and b, ignoring case, punctuation and stop words. It ranges
+// from 0 (no words in common) to 1 (same words).
+func Similarity(a, b string) float64 {
+	ta, tb := terms(a), terms(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for term, ca := range ta {
+		dot += float64(ca * tb[term])
+		na += float64(ca * ca)
+	}
+	for _, cb := range tb {
+		nb += float64(cb * cb)
+	}
+
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func terms(s string) map[string]int {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	counts := map[string]int{}
+	for _, w := range words {
+		if len(w) < 2 || stopWords[w] {
+			continue
+		}
+		counts[w]++
+	}
+	return counts
+}
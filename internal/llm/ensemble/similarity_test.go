@@ -0,0 +1,108 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"same words", "Fed cuts rates", "rates Fed cuts", 1},
+		{"case and punctuation", "Fed cuts rates.", "FED CUTS RATES", 1},
+		{"stop words ignored", "The Fed will cut rates", "Fed cut rates", 1},
+		{"no words in common", "Fed cuts rates", "Bitcoin rallies", 0},
+		{"half in common", "Fed cuts rates", "Fed holds", 1 / math.Sqrt(6)},
+		{"empty", "", "Fed cuts rates", 0},
+		{"only stop words", "the will be", "the will be", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeEmbedder embeds known texts as fixed vectors.
+type fakeEmbedder struct {
+	vectors map[string][]float32
+	err     error
+}
+
+func (e fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.vectors[text]
+	}
+	return vectors, nil
+}
+
+func (e fakeEmbedder) ModelName() string {
+	return "fake"
+}
+
+func forecast(outcomes ...string) *dto.Forecast {
+	f := &dto.Forecast{}
+	for _, o := range outcomes {
+		f.Outcomes = append(f.Outcomes, dto.Outcome{Content: o, ConfidenceLevel: 60})
+	}
+	return f
+}
+
+func TestMatcherCluster(t *testing.T) {
+	const (
+		cut     = "The Fed cuts its policy rate in September"
+		lowered = "Interest rates are lowered by the central bank next month"
+		hold    = "The Fed keeps its policy rate unchanged in September"
+	)
+
+	// cut and lowered share no words but mean the same; cut and hold share
+	// most words but don't
+	embedder := fakeEmbedder{vectors: map[string][]float32{
+		cut:     {1, 0.1, 0},
+		lowered: {0.95, 0.15, 0},
+		hold:    {0, 1, 0.2},
+	}}
+
+	tests := []struct {
+		name    string
+		matcher Matcher
+		want    [][]string
+	}{
+		{"semantic", NewMatcher(embedder), [][]string{{cut, lowered}, {hold, hold}}},
+		{"lexical without embedder", NewMatcher(nil), [][]string{{cut}, {hold, hold}, {lowered}}},
+		{"lexical when embedding fails", NewMatcher(fakeEmbedder{err: errors.New("down")}), [][]string{{cut}, {hold, hold}, {lowered}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := tt.matcher.Cluster(context.Background(), []string{"a", "b"}, []*dto.Forecast{
+				forecast(cut, hold),
+				forecast(lowered, hold),
+			})
+
+			got := make([][]string, len(clusters))
+			for i, c := range clusters {
+				for _, contribution := range c.Contributions {
+					got[i] = append(got[i], contribution.Content)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clusters = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
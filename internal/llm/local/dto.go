@@ -0,0 +1,67 @@
+package local
+
+// OllamaRequest is the payload for Ollama's /api/generate endpoint.
+type OllamaRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Format  string        `json:"format,omitempty"`
+	Options OllamaOptions `json:"options"`
+}
+
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+}
+
+type OllamaResponse struct {
+	Model    string `json:"model"`
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	// Token counts reported once generation is done
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// LlamaCppRequest is the payload for the llama.cpp server's /completion endpoint.
+type LlamaCppRequest struct {
+	Prompt      string                 `json:"prompt"`
+	NPredict    int                    `json:"n_predict,omitempty"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	Grammar     string                 `json:"grammar,omitempty"`
+	JSONSchema  map[string]interface{} `json:"json_schema,omitempty"`
+	Stream      bool                   `json:"stream"`
+}
+
+type LlamaCppResponse struct {
+	Content         string `json:"content"`
+	Stop            bool   `json:"stop"`
+	TokensEvaluated int    `json:"tokens_evaluated"`
+	TokensPredicted int    `json:"tokens_predicted"`
+}
+
+// OllamaEmbedRequest is the payload for Ollama's /api/embed endpoint.
+type OllamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type OllamaEmbedResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// LlamaCppEmbeddingRequest is the payload for the llama.cpp server's
+// OpenAI-compatible /v1/embeddings endpoint.
+type LlamaCppEmbeddingRequest struct {
+	Input []string `json:"input"`
+}
+
+type LlamaCppEmbeddingResponse struct {
+	Data []LlamaCppEmbedding `json:"data"`
+}
+
+type LlamaCppEmbedding struct {
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
@@ -0,0 +1,43 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"github.com/qoentz/evedict/internal/llm"
+)
+
+var _ llm.Embedder = &Service{}
+
+// Embed needs a server started with an embedding model; llama.cpp has to run
+// with --embeddings.
+func (s *Service) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+
+	switch s.Server {
+	case LlamaCpp:
+		var result LlamaCppEmbeddingResponse
+		if err := s.post(ctx, "/v1/embeddings", LlamaCppEmbeddingRequest{Input: texts}, &result); err != nil {
+			return nil, err
+		}
+
+		vectors = make([][]float32, len(result.Data))
+		for _, e := range result.Data {
+			if e.Index < 0 || e.Index >= len(vectors) {
+				return nil, fmt.Errorf("embedding index %d out of range", e.Index)
+			}
+			vectors[e.Index] = e.Embedding
+		}
+	default:
+		var result OllamaEmbedResponse
+		if err := s.post(ctx, "/api/embed", OllamaEmbedRequest{Model: s.Model, Input: texts}, &result); err != nil {
+			return nil, err
+		}
+		vectors = result.Embeddings
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,170 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/qoentz/evedict/internal/llm"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type Server string
+
+const (
+	Ollama   Server = "ollama"
+	LlamaCpp Server = "llamacpp"
+)
+
+// Service runs prompts against a model served on the local machine by
+// Ollama or the llama.cpp HTTP server, so the pipeline works without any
+// paid API key.
+type Service struct {
+	HTTPClient  *http.Client
+	Server      Server
+	BaseURL     string
+	Model       string
+	Temperature *float64
+	// Grammar is an optional GBNF grammar applied to JSON requests on llama.cpp.
+	Grammar string
+}
+
+var _ llm.Provider = &Service{}
+
+func NewLocalService(client *http.Client, server Server, baseURL, model string, temperature *float64, grammar string) *Service {
+	return &Service{
+		HTTPClient:  client,
+		Server:      server,
+		BaseURL:     strings.TrimSuffix(baseURL, "/"),
+		Model:       model,
+		Temperature: temperature,
+		Grammar:     grammar,
+	}
+}
+
+func ParseServer(server string) (Server, error) {
+	switch Server(server) {
+	case "", Ollama:
+		return Ollama, nil
+	case LlamaCpp:
+		return LlamaCpp, nil
+	default:
+		return "", fmt.Errorf("invalid local LLM server: %s", server)
+	}
+}
+
+func (s *Service) ModelName() string {
+	if s.Model == "" {
+		return string(s.Server)
+	}
+	return s.Model
+}
+
+func (s *Service) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	switch s.Server {
+	case LlamaCpp:
+		return s.completeLlamaCpp(ctx, req)
+	default:
+		return s.completeOllama(ctx, req)
+	}
+}
+
+func (s *Service) completeOllama(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	payload := OllamaRequest{
+		Model:  llm.ModelFor(s, req),
+		Prompt: req.Prompt,
+		Stream: false,
+		Options: OllamaOptions{
+			Temperature: s.temperature(req),
+			NumPredict:  req.MaxTokens,
+		},
+	}
+
+	if req.JSON {
+		payload.Format = "json"
+	}
+
+	var result OllamaResponse
+	if err := s.post(ctx, "/api/generate", payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &llm.Response{
+		Output: strings.TrimSpace(result.Response),
+		Usage: &llm.Usage{
+			PromptTokens:     result.PromptEvalCount,
+			CompletionTokens: result.EvalCount,
+		},
+	}, nil
+}
+
+func (s *Service) completeLlamaCpp(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	payload := LlamaCppRequest{
+		Prompt:      req.Prompt,
+		NPredict:    req.MaxTokens,
+		Temperature: s.temperature(req),
+		Stream:      false,
+	}
+
+	if req.JSON {
+		if s.Grammar != "" {
+			payload.Grammar = s.Grammar
+		} else {
+			payload.JSONSchema = map[string]interface{}{"type": "object"}
+		}
+	}
+
+	var result LlamaCppResponse
+	if err := s.post(ctx, "/completion", payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &llm.Response{
+		Output: strings.TrimSpace(result.Content),
+		Usage: &llm.Usage{
+			PromptTokens:     result.TokensEvaluated,
+			CompletionTokens: result.TokensPredicted,
+		},
+	}, nil
+}
+
+// temperature prefers the per-request value; without either, the server's
+// default stays in place.
+func (s *Service) temperature(req llm.Request) *float64 {
+	if req.Temperature != nil {
+		return req.Temperature
+	}
+	return s.Temperature
+}
+
+func (s *Service) post(ctx context.Context, path string, payload interface{}, result interface{}) error {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling request body: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error parsing response JSON: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/qoentz/evedict/internal/llm"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var temperature = 0.2
+
+// serve answers every request with body and keeps the path and the decoded
+// payload of the last one.
+func serve(t *testing.T, body string) (*httptest.Server, *string, *map[string]any) {
+	t.Helper()
+
+	var path string
+	payload := map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &path, &payload
+}
+
+func TestCompleteOllama(t *testing.T) {
+	server, path, payload := serve(t, `{"model": "llama3", "response": " {\"headline\": \"Fed holds\"}\n", "done": true}`)
+
+	s := NewLocalService(server.Client(), Ollama, server.URL+"/", "llama3", &temperature, "")
+	resp, err := s.Complete(context.Background(), llm.Request{Prompt: "Forecast the Fed", MaxTokens: 256, JSON: true})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if *path != "/api/generate" {
+		t.Errorf("path = %q", *path)
+	}
+	want := map[string]any{
+		"model":   "llama3",
+		"prompt":  "Forecast the Fed",
+		"stream":  false,
+		"format":  "json",
+		"options": map[string]any{"temperature": 0.2, "num_predict": float64(256)},
+	}
+	if !reflect.DeepEqual(*payload, want) {
+		t.Errorf("payload = %v, want %v", *payload, want)
+	}
+	if resp.Output != `{"headline": "Fed holds"}` {
+		t.Errorf("Output = %q", resp.Output)
+	}
+}
+
+func TestCompleteLlamaCpp(t *testing.T) {
+	tests := []struct {
+		name    string
+		grammar string
+		json    bool
+		want    map[string]any
+	}{
+		{
+			name: "text",
+			want: map[string]any{"prompt": "Keywords", "n_predict": float64(64), "temperature": 0.2, "stream": false},
+		},
+		{
+			name: "JSON schema",
+			json: true,
+			want: map[string]any{"prompt": "Keywords", "n_predict": float64(64), "temperature": 0.2, "stream": false, "json_schema": map[string]any{"type": "object"}},
+		},
+		{
+			name:    "grammar",
+			grammar: `root ::= "{" "}"`,
+			json:    true,
+			want:    map[string]any{"prompt": "Keywords", "n_predict": float64(64), "temperature": 0.2, "stream": false, "grammar": `root ::= "{" "}"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, path, payload := serve(t, `{"content": "fed, rates\n", "stop": true}`)
+
+			s := NewLocalService(server.Client(), LlamaCpp, server.URL, "", &temperature, tt.grammar)
+			resp, err := s.Complete(context.Background(), llm.Request{Prompt: "Keywords", MaxTokens: 64, JSON: tt.json})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			if *path != "/completion" {
+				t.Errorf("path = %q", *path)
+			}
+			if !reflect.DeepEqual(*payload, tt.want) {
+				t.Errorf("payload = %v, want %v", *payload, tt.want)
+			}
+			if resp.Output != "fed, rates" {
+				t.Errorf("Output = %q", resp.Output)
+			}
+		})
+	}
+}
+
+func TestTemperature(t *testing.T) {
+	zero, request := 0.0, 0.7
+
+	tests := []struct {
+		name       string
+		configured *float64
+		request    *float64
+		want       any
+	}{
+		{"unset", nil, nil, nil},
+		{"configured zero", &zero, nil, 0.0},
+		{"configured", &temperature, nil, 0.2},
+		{"request wins", &zero, &request, 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _, payload := serve(t, `{"content": "fed", "stop": true}`)
+
+			s := NewLocalService(server.Client(), LlamaCpp, server.URL, "", tt.configured, "")
+			if _, err := s.Complete(context.Background(), llm.Request{Prompt: "Keywords", Temperature: tt.request}); err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+			if got := (*payload)["temperature"]; got != tt.want {
+				t.Errorf("temperature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	s := NewLocalService(server.Client(), Ollama, server.URL, "missing", &temperature, "")
+	if _, err := s.Complete(context.Background(), llm.Request{Prompt: "x"}); err == nil {
+		t.Error("Complete() returned no error for a 404")
+	}
+}
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		server  string
+		want    Server
+		wantErr bool
+	}{
+		{"", Ollama, false},
+		{"ollama", Ollama, false},
+		{"llamacpp", LlamaCpp, false},
+		{"vllm", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseServer(tt.server)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseServer(%q) = %q, %v", tt.server, got, err)
+		}
+	}
+}
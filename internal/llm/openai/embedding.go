@@ -0,0 +1,70 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/qoentz/evedict/internal/llm"
+	"io"
+	"net/http"
+)
+
+var _ llm.Embedder = &Service{}
+
+// Embed uses the /embeddings endpoint with Model as the embedding model.
+func (s *Service) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody, err := json.Marshal(EmbeddingRequest{
+		Model: s.Model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request body: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/embeddings", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+
+	resp, err := s.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	}
+
+	var result EmbeddingResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error parsing response JSON: %v", err)
+	}
+
+	return orderEmbeddings(result.Data, len(texts))
+}
+
+// orderEmbeddings puts the returned vectors back in the order of the input
+// texts.
+func orderEmbeddings(data []Embedding, n int) ([][]float32, error) {
+	if len(data) != n {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", n, len(data))
+	}
+
+	vectors := make([][]float32, n)
+	for _, e := range data {
+		if e.Index < 0 || e.Index >= n {
+			return nil, fmt.Errorf("embedding index %d out of range", e.Index)
+		}
+		vectors[e.Index] = e.Embedding
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,107 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/qoentz/evedict/internal/llm"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	systemPrompt     = "You are an analyst for a news forecasting service. Follow the instructions in the user message exactly and return only the requested output."
+	systemPromptJSON = systemPrompt + " Respond with a single valid JSON object and nothing else."
+)
+
+// Service talks to any server exposing the OpenAI /v1/chat/completions protocol,
+// such as OpenAI itself, vLLM, LM Studio or a hosted gateway.
+type Service struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Model      string
+	APIKey     string
+}
+
+var _ llm.Provider = &Service{}
+
+func NewOpenAIService(client *http.Client, baseURL, model, apiKey string) *Service {
+	return &Service{
+		HTTPClient: client,
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Model:      model,
+		APIKey:     apiKey,
+	}
+}
+
+func (s *Service) ModelName() string {
+	return s.Model
+}
+
+func (s *Service) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	system := systemPrompt
+	if req.JSON {
+		system = systemPromptJSON
+	}
+
+	payload := ChatRequest{
+		Model: llm.ModelFor(s, req),
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: req.Prompt},
+		},
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	}
+
+	if req.JSON {
+		payload.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request body: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.BaseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+
+	resp, err := s.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	}
+
+	var completion ChatResponse
+	if err = json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("error parsing response JSON: %v", err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("completion %s returned no choices", completion.ID)
+	}
+
+	result := &llm.Response{Output: strings.TrimSpace(completion.Choices[0].Message.Content)}
+	if completion.Usage != nil {
+		result.Usage = &llm.Usage{
+			PromptTokens:     completion.Usage.PromptTokens,
+			CompletionTokens: completion.Usage.CompletionTokens,
+		}
+	}
+
+	return result, nil
+}
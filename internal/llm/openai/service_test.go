@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/qoentz/evedict/internal/llm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	var got ChatRequest
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Write([]byte(`{"id": "chatcmpl-1", "model": "gpt-test", "choices": [{"index": 0, "message": {"role": "assistant", "content": "  {\"headline\": \"Fed holds\"}\n"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	s := NewOpenAIService(server.Client(), server.URL+"/v1/", "gpt-test", "secret")
+	resp, err := s.Complete(context.Background(), llm.Request{Prompt: "Forecast the Fed", MaxTokens: 256, JSON: true})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if path != "/v1/chat/completions" {
+		t.Errorf("path = %q", path)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Model != "gpt-test" || got.MaxTokens != 256 {
+		t.Errorf("request model %q with %d max tokens", got.Model, got.MaxTokens)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Role != "user" || got.Messages[1].Content != "Forecast the Fed" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if !strings.Contains(got.Messages[0].Content, "JSON") {
+		t.Errorf("system prompt %q doesn't ask for JSON", got.Messages[0].Content)
+	}
+	if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("response_format = %+v, want json_object", got.ResponseFormat)
+	}
+
+	if resp.Output != `{"headline": "Fed holds"}` {
+		t.Errorf("Output = %q", resp.Output)
+	}
+}
+
+func TestCompleteText(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("sent Authorization without an API key")
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices": [{"message": {"content": "fed, rates"}}]}`))
+	}))
+	defer server.Close()
+
+	s := NewOpenAIService(server.Client(), server.URL, "local", "")
+	resp, err := s.Complete(context.Background(), llm.Request{Prompt: "Keywords"})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Output != "fed, rates" {
+		t.Errorf("Output = %q", resp.Output)
+	}
+	if _, ok := got["response_format"]; ok {
+		t.Errorf("sent response_format for a text request")
+	}
+	if _, ok := got["max_tokens"]; ok {
+		t.Errorf("sent max_tokens without a limit")
+	}
+}
+
+func TestCompleteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"status", http.StatusTooManyRequests, `{"error": "rate limited"}`, "rate limited"},
+		{"no choices", http.StatusOK, `{"id": "chatcmpl-2", "choices": []}`, "no choices"},
+		{"bad JSON", http.StatusOK, `not json`, "error parsing response JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := NewOpenAIService(server.Client(), server.URL, "gpt-test", "")
+			if _, err := s.Complete(context.Background(), llm.Request{Prompt: "x"}); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Complete() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"log"
+	"strings"
+	"time"
+)
+
+// PromptService implements Service on top of any Provider by rendering the
+// prompt templates and parsing the model output.
+type PromptService struct {
+	Provider       Provider
+	PromptTemplate *promptgen.PromptTemplate
+	// MaxAttempts is how often a forecast is requested before invalid output is given up on.
+	MaxAttempts int
+	// Recorder, if set, is told about every forecast answer and whether it
+	// passed validation.
+	Recorder AttemptRecorder
+}
+
+type AttemptRecorder interface {
+	SaveForecastAttempt(attempt *ForecastAttempt) error
+}
+
+// ForecastAttempt is one forecast answer of the model. Err is why it failed
+// validation, or nil when it passed.
+type ForecastAttempt struct {
+	RunID         *uuid.UUID
+	TemplateType  promptgen.TemplateType
+	PromptVariant string
+	PromptHash    string
+	Model         string
+	Attempt       int
+	Err           error
+	CreatedAt     time.Time
+}
+
+const DefaultMaxAttempts = 3
+
+// repairPrompt re-sends the original prompt together with the rejected answer
+// and the reason it was rejected.
+const repairPrompt = `%s
+
+Your previous answer was:
+%s
+
+It was rejected because:
+%v
+
+Answer again. Return only the corrected JSON object, without markdown fences or any other text.`
+
+var _ Service = &PromptService{}
+
+func NewPromptService(provider Provider, promptTemplate *promptgen.PromptTemplate, maxAttempts int, recorder AttemptRecorder) *PromptService {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &PromptService{
+		Provider:       provider,
+		PromptTemplate: promptTemplate,
+		MaxAttempts:    maxAttempts,
+		Recorder:       recorder,
+	}
+}
+
+func (s *PromptService) GetForecast(ctx context.Context, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error) {
+	if mainArticle.Title == "" {
+		return nil, fmt.Errorf("main article is missing a title")
+	}
+
+	templateType := promptgen.GenerateNewsForecast
+	if event != nil {
+		templateType = promptgen.GenerateMarketForecast
+	}
+	name := pickVariant(ctx, s.PromptTemplate, templateType)
+
+	render := func(mainArticle eventfeed.Article, relatedArticles []eventfeed.Article) (string, promptgen.PromptVersion, error) {
+		var data interface{} = promptgen.NewsForecastData{
+			MainArticle:     mainArticle,
+			RelatedArticles: relatedArticles,
+		}
+		if event != nil {
+			data = promptgen.MarketForecastData{
+				MainArticle:     mainArticle,
+				RelatedArticles: relatedArticles,
+				Event:           *event,
+			}
+		}
+
+		prompt, version, err := s.PromptTemplate.RenderVariant(name, data)
+		if err != nil {
+			return "", promptgen.PromptVersion{}, fmt.Errorf("error creating forecast prompt: %v", err)
+		}
+
+		return prompt, version, nil
+	}
+
+	return s.generateForecast(ctx, templateType, mainArticle, relatedArticles, render)
+}
+
+// generateForecast asks the model for a forecast and, when the answer fails
+// validation, asks again with the validation error until MaxAttempts is used up.
+// Every attempt is fitted into the prompt budget, see fitPrompt, as the
+// rejected answer makes the prompt grow.
+func (s *PromptService) generateForecast(ctx context.Context, templateType promptgen.TemplateType, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, render func(eventfeed.Article, []eventfeed.Article) (string, promptgen.PromptVersion, error)) (*dto.Forecast, error) {
+	budget := promptBudget(s.Provider, templateType)
+
+	var version promptgen.PromptVersion
+	var rejected string
+	var rejectedErr error
+	attemptRender := func(mainArticle eventfeed.Article, relatedArticles []eventfeed.Article) (string, error) {
+		prompt, v, err := render(mainArticle, relatedArticles)
+		if err != nil {
+			return "", err
+		}
+		version = v
+
+		if rejectedErr != nil {
+			prompt = fmt.Sprintf(repairPrompt, prompt, rejected, rejectedErr)
+		}
+		return prompt, nil
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		prompt, related, err := fitPrompt(budget, mainArticle, relatedArticles, attemptRender)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.request(ctx, templateType, prompt, true)
+		if err != nil {
+			return nil, err
+		}
+		output := resp.Output
+
+		result, err := ParseForecast(output)
+		if err == nil {
+			err = ValidateSources(result, len(related))
+		}
+		s.recordAttempt(ctx, templateType, version, resp.Model, attempt, err)
+
+		if err == nil {
+			resp.Accepted()
+			result.Provenance = &dto.Provenance{
+				TemplateType:  string(templateType),
+				PromptVersion: version.Version,
+				PromptHash:    version.Hash,
+				PromptVariant: version.Variant,
+				Model:         resp.Model,
+			}
+			return result, nil
+		}
+
+		log.Printf("Forecast attempt %d/%d rejected: %v", attempt, s.MaxAttempts, err)
+		lastErr = fmt.Errorf("%v\nOutput Data:\n%s", err, output)
+		rejected, rejectedErr = output, err
+	}
+
+	return nil, fmt.Errorf("error parsing forecast output after %d attempts: %v", s.MaxAttempts, lastErr)
+}
+
+func (s *PromptService) recordAttempt(ctx context.Context, templateType promptgen.TemplateType, version promptgen.PromptVersion, modelName string, attempt int, parseErr error) {
+	if s.Recorder == nil {
+		return
+	}
+
+	record := &ForecastAttempt{
+		TemplateType:  templateType,
+		PromptVariant: version.Variant,
+		PromptHash:    version.Hash,
+		Model:         modelName,
+		Attempt:       attempt,
+		Err:           parseErr,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	if runID, ok := RunIDFromContext(ctx); ok {
+		record.RunID = &runID
+	}
+
+	if err := s.Recorder.SaveForecastAttempt(record); err != nil {
+		log.Printf("Error recording forecast attempt: %v", err)
+	}
+}
+
+func (s *PromptService) SelectIndexes(ctx context.Context, templateType promptgen.TemplateType, data interface{}, minSelection int) ([]int, error) {
+	prompt, err := s.PromptTemplate.CreatePrompt(templateType, data)
+	if err != nil {
+		return nil, fmt.Errorf("error creating prompt for %s: %v", templateType, err)
+	}
+
+	resp, err := s.request(ctx, templateType, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	outputStr := resp.Output
+
+	var selection struct {
+		Selected []int `json:"selected"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(outputStr)), &selection); err != nil {
+		return nil, fmt.Errorf("error parsing selection output: %v\nOutput Data:\n%s", err, outputStr)
+	}
+
+	if len(selection.Selected) < minSelection {
+		return nil, fmt.Errorf("expected at least %d selected items, got %d: %v", minSelection, len(selection.Selected), selection.Selected)
+	}
+
+	resp.Accepted()
+	return selection.Selected, nil
+}
+
+func (s *PromptService) SelectIndex(ctx context.Context, templateType promptgen.TemplateType, data interface{}) (int, error) {
+	prompt, err := s.PromptTemplate.CreatePrompt(templateType, data)
+	if err != nil {
+		return -1, fmt.Errorf("error creating prompt for %s: %v", templateType, err)
+	}
+
+	resp, err := s.request(ctx, templateType, prompt, true)
+	if err != nil {
+		return -1, err
+	}
+	outputStr := resp.Output
+
+	var selection struct {
+		Selected int `json:"selected"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(outputStr)), &selection); err != nil {
+		return -1, fmt.Errorf("error parsing single selection output: %v\nOutput Data:\n%s", err, outputStr)
+	}
+
+	resp.Accepted()
+	return selection.Selected, nil
+}
+
+func (s *PromptService) ExtractKeywords(ctx context.Context, article eventfeed.Article) ([]string, error) {
+	prompt, err := s.PromptTemplate.CreatePrompt(promptgen.ExtractKeywords, article)
+	if err != nil {
+		return nil, fmt.Errorf("error creating keyword extraction prompt: %v", err)
+	}
+
+	resp, err := s.request(ctx, promptgen.ExtractKeywords, prompt, false)
+	if err != nil {
+		return nil, err
+	}
+	outputStr := resp.Output
+
+	keywords := strings.Split(strings.TrimSpace(outputStr), ",")
+	if len(keywords) != 2 {
+		return nil, fmt.Errorf("expected 2 keywords, got %d: %v", len(keywords), keywords)
+	}
+
+	for i := range keywords {
+		keywords[i] = strings.TrimSpace(keywords[i])
+	}
+
+	resp.Accepted()
+	return keywords, nil
+}
+
+func (s *PromptService) TranslateArticle(ctx context.Context, article eventfeed.Article, language string) (*eventfeed.Article, error) {
+	prompt, err := s.PromptTemplate.CreatePrompt(promptgen.TranslateArticle, promptgen.TranslateArticleData{
+		Language: language,
+		Article:  article,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating translation prompt: %v", err)
+	}
+
+	resp, err := s.request(ctx, promptgen.TranslateArticle, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	outputStr := resp.Output
+
+	var translation struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Content     string `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(outputStr)), &translation); err != nil {
+		return nil, fmt.Errorf("error parsing translation output: %v\nOutput Data:\n%s", err, outputStr)
+	}
+
+	if strings.TrimSpace(translation.Title) == "" {
+		return nil, fmt.Errorf("translation is missing the title\nOutput Data:\n%s", outputStr)
+	}
+
+	translated := article
+	// The prompt fences the article, which the model may copy into its answer
+	translated.Title = promptgen.Sanitize(translation.Title)
+	translated.Description = promptgen.Sanitize(translation.Description)
+	translated.Content = promptgen.Sanitize(translation.Content)
+	translated.Language = "en"
+
+	resp.Accepted()
+	return &translated, nil
+}
+
+// request sends the prompt to the provider. Callers call Accepted on the
+// response once its output passed their checks.
+func (s *PromptService) request(ctx context.Context, templateType promptgen.TemplateType, prompt string, jsonOutput bool) (*Response, error) {
+	if len(prompt) == 0 {
+		return nil, fmt.Errorf("empty prompt provided")
+	}
+
+	req := Request{
+		TemplateType: templateType,
+		Prompt:       prompt,
+		JSON:         jsonOutput,
+		Sample:       SampleFromContext(ctx),
+	}
+
+	resp, err := s.Provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Output = strings.TrimSpace(resp.Output)
+	if resp.Model == "" {
+		resp.Model = ModelFor(s.Provider, req)
+	}
+
+	return resp, nil
+}
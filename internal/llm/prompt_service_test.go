@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scriptedProvider answers with outputs in turn and keeps the prompts and
+// the accepted outputs.
+type scriptedProvider struct {
+	outputs  []string
+	budget   int
+	prompts  []string
+	accepted []string
+}
+
+func (p *scriptedProvider) Complete(_ context.Context, req Request) (*Response, error) {
+	p.prompts = append(p.prompts, req.Prompt)
+	output := p.outputs[0]
+	p.outputs = p.outputs[1:]
+	return &Response{
+		Output: output,
+		Accept: func() { p.accepted = append(p.accepted, output) },
+	}, nil
+}
+
+func (p *scriptedProvider) ModelName() string {
+	return "scripted"
+}
+
+func (p *scriptedProvider) PromptBudget(promptgen.TemplateType) int {
+	return p.budget
+}
+
+// attemptLog keeps the recorded forecast attempts.
+type attemptLog []*ForecastAttempt
+
+func (l *attemptLog) SaveForecastAttempt(attempt *ForecastAttempt) error {
+	*l = append(*l, attempt)
+	return nil
+}
+
+func loadPrompts(t *testing.T, prompts string) *promptgen.PromptTemplate {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	if err := os.WriteFile(path, []byte(prompts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := promptgen.LoadPromptTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+	return p
+}
+
+const forecastPrompts = `
+generate_news_forecast: |
+  Forecast {{.MainArticle.Title}}.
+`
+
+func TestGetForecastRepromptsInvalidOutput(t *testing.T) {
+	valid := `{"headline": "Fed holds", "summary": "Rates stay.", "outcomes": [{"content": "Cut in September", "confidenceLevel": 60}]}`
+
+	tests := []struct {
+		name       string
+		outputs    []string
+		wantErr    bool
+		wantReason string
+	}{
+		{"valid", []string{valid}, false, ""},
+		{"fenced", []string{"```json\n" + valid + "\n```"}, false, ""},
+		{"invalid JSON", []string{`{"headline": "Fed holds",`, valid}, false, "not valid JSON"},
+		{"missing outcomes", []string{`{"headline": "Fed holds", "summary": "Rates stay."}`, valid}, false, `"outcomes" must contain at least one outcome`},
+		{"gives up", []string{"no", "still no", "never"}, true, "not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &scriptedProvider{outputs: tt.outputs, budget: 1 << 20}
+			s := NewPromptService(provider, loadPrompts(t, forecastPrompts), 3, nil)
+
+			forecast, err := s.GetForecast(context.Background(), eventfeed.Article{Title: "Fed", Description: "Rates"}, nil, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			} else if err != nil {
+				t.Fatalf("GetForecast() error: %v", err)
+			} else if forecast.Headline != "Fed holds" {
+				t.Errorf("Headline = %q", forecast.Headline)
+			}
+
+			if len(provider.prompts) != len(tt.outputs) {
+				t.Fatalf("got %d requests, want %d", len(provider.prompts), len(tt.outputs))
+			}
+			if !strings.HasPrefix(provider.prompts[0], "Forecast Fed.\n") {
+				t.Errorf("first prompt = %q", provider.prompts[0])
+			}
+			for i, prompt := range provider.prompts[1:] {
+				if !strings.HasPrefix(prompt, provider.prompts[0]) || !strings.Contains(prompt, "Your previous answer was:\n"+tt.outputs[i]) || !strings.Contains(prompt, tt.wantReason) {
+					t.Errorf("repair prompt %d doesn't carry the rejected answer and why:\n%s", i+1, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestVariantsShareTheTemplateRoute(t *testing.T) {
+	prompts := loadPrompts(t, `
+generate_news_forecast:
+  weight: 0
+  template: Forecast {{.MainArticle.Title}}.
+generate_news_forecast@short: Short {{.MainArticle.Title}}.
+`)
+
+	forecaster := &scriptedProvider{outputs: []string{`{"headline": "Fed holds", "summary": "Rates stay.", "outcomes": [{"content": "Cut", "confidenceLevel": 60}]}`}}
+	fallback := &recordingProvider{name: "fallback"}
+	router := NewRouter(Route{Provider: fallback}, map[promptgen.TemplateType]Route{
+		promptgen.GenerateNewsForecast: {Provider: forecaster},
+	})
+
+	s := NewPromptService(router, prompts, 1, nil)
+	forecast, err := s.GetForecast(context.Background(), eventfeed.Article{Title: "Fed", Description: "Rates"}, nil, nil)
+	if err != nil {
+		t.Fatalf("GetForecast() error: %v", err)
+	}
+
+	if len(forecaster.prompts) != 1 || !strings.HasPrefix(forecaster.prompts[0], "Short Fed.") {
+		t.Errorf("forecast route got %q, want the short variant", forecaster.prompts)
+	}
+	if len(fallback.requests) != 0 {
+		t.Errorf("the variant went to the default route")
+	}
+	if forecast.Provenance.PromptVariant != "short" {
+		t.Errorf("PromptVariant = %q", forecast.Provenance.PromptVariant)
+	}
+}
+
+func TestRepairPromptIsFitted(t *testing.T) {
+	prompts := loadPrompts(t, `
+generate_news_forecast: |
+  {{.MainArticle.Title}}
+  {{range $i, $a := .RelatedArticles}}[{{$i}}] {{$a.Title}}: {{$a.Description}}
+  {{end}}
+`)
+
+	related := make([]eventfeed.Article, 4)
+	for i := range related {
+		related[i] = eventfeed.Article{Title: "Related", Description: strings.Repeat("word ", 40)}
+	}
+
+	rejected := `{"headline": "", "summary": "` + strings.Repeat("long ", 100) + `"}`
+	valid := `{"headline": "Fed holds", "summary": "Rates stay.", "outcomes": [{"content": "Cut in September", "confidenceLevel": 60, "sources": [0]}]}`
+
+	provider := &scriptedProvider{outputs: []string{rejected, valid}}
+	attempts := &attemptLog{}
+	s := NewPromptService(provider, prompts, 2, attempts)
+
+	// Room for the first prompt but not for it with the rejected answer
+	first, _, err := fitPrompt(1<<20, eventfeed.Article{Title: "Fed", Description: "Rates"}, related, func(main eventfeed.Article, related []eventfeed.Article) (string, error) {
+		prompt, _, err := prompts.Render(promptgen.GenerateNewsForecast, promptgen.NewsForecastData{MainArticle: main, RelatedArticles: related})
+		return prompt, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.budget = int(float64(EstimateTokens(first)+50) / budgetShare)
+
+	forecast, err := s.GetForecast(context.Background(), eventfeed.Article{Title: "Fed", Description: "Rates"}, related, nil)
+	if err != nil {
+		t.Fatalf("GetForecast() error: %v", err)
+	}
+	if forecast.Headline != "Fed holds" {
+		t.Errorf("Headline = %q", forecast.Headline)
+	}
+
+	if len(provider.prompts) != 2 {
+		t.Fatalf("got %d requests, want 2", len(provider.prompts))
+	}
+	limit := int(float64(provider.budget) * budgetShare)
+	for i, prompt := range provider.prompts {
+		if n := EstimateTokens(prompt); n > limit {
+			t.Errorf("prompt %d has %d tokens, over the limit of %d", i+1, n, limit)
+		}
+	}
+	if !strings.Contains(provider.prompts[1], "Your previous answer was") {
+		t.Errorf("second prompt is not a repair prompt:\n%s", provider.prompts[1])
+	}
+	if strings.Count(provider.prompts[1], "Related:") >= strings.Count(provider.prompts[0], "Related:") {
+		t.Errorf("repair prompt kept every related article")
+	}
+
+	if len(provider.accepted) != 1 || provider.accepted[0] != valid {
+		t.Errorf("accepted %q, want only the valid answer", provider.accepted)
+	}
+
+	if len(*attempts) != 2 || (*attempts)[0].Err == nil || (*attempts)[1].Err != nil {
+		t.Errorf("recorded attempts %+v, want a rejected then an accepted one", *attempts)
+	}
+	for _, attempt := range *attempts {
+		if attempt.TemplateType != promptgen.GenerateNewsForecast || attempt.Model != "scripted" || attempt.PromptHash == "" {
+			t.Errorf("recorded attempt %+v", attempt)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/promptgen"
+)
+
+// Provider sends a rendered prompt to a model backend and returns its raw output.
+type Provider interface {
+	Complete(ctx context.Context, req Request) (*Response, error)
+	// ModelName identifies the model answering the requests.
+	ModelName() string
+}
+
+type Request struct {
+	TemplateType promptgen.TemplateType
+	Prompt       string
+	MaxTokens    int
+	JSON         bool
+	// Model and Temperature override the provider's defaults when set.
+	Model       string
+	Temperature *float64
+	// Sample numbers repeated requests for the same prompt, so each one is
+	// answered and cached separately. Zero means a single request.
+	Sample int
+}
+
+// ModelFor returns the model that will answer req on p.
+func ModelFor(p Provider, req Request) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return p.ModelName()
+}
+
+type Response struct {
+	Output string
+	// Usage is nil when the backend doesn't report token counts.
+	Usage *Usage
+	// Model is the model that answered, when known.
+	Model string
+	// Accept is set by decorators that only act on output that passed
+	// validation, such as the cache. Callers call it through Accepted.
+	Accept func()
+}
+
+// Accepted tells the providers that the output passed validation.
+func (r *Response) Accepted() {
+	if r.Accept != nil {
+		r.Accept()
+	}
+}
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
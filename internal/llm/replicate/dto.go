@@ -6,8 +6,9 @@ type RequestPayload struct {
 }
 
 type Input struct {
-	Prompt    string `json:"prompt"`
-	MaxTokens int    `json:"max_tokens"`
+	Prompt      string   `json:"prompt"`
+	MaxTokens   int      `json:"max_tokens"`
+	Temperature *float64 `json:"temperature,omitempty"`
 }
 
 type ResponsePayload struct {
@@ -16,10 +17,18 @@ type ResponsePayload struct {
 	Version string      `json:"version"`
 	Status  string      `json:"status"`
 	Output  interface{} `json:"output"`
+	Error   interface{} `json:"error"`
 	URLs    URLs        `json:"urls"`
+	Metrics Metrics     `json:"metrics"`
 	Stream  bool        `json:"stream"`
 }
 
+type Metrics struct {
+	InputTokenCount  int     `json:"input_token_count"`
+	OutputTokenCount int     `json:"output_token_count"`
+	PredictTime      float64 `json:"predict_time"`
+}
+
 type URLs struct {
 	Cancel string `json:"cancel"`
 	Get    string `json:"get"`
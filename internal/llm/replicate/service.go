@@ -1,177 +1,91 @@
 package replicate
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/qoentz/evedict/internal/api/dto"
-	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
-	"github.com/qoentz/evedict/internal/eventfeed/polymarket"
 	"github.com/qoentz/evedict/internal/llm"
-	"github.com/qoentz/evedict/internal/promptgen"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 )
 
+const (
+	pollInterval = 2 * time.Second
+	// predictionTimeout bounds a whole prediction, including queueing on
+	// Replicate's side, when the caller sets no earlier deadline.
+	predictionTimeout = 5 * time.Minute
+	cancelTimeout     = 10 * time.Second
+)
+
+const modelsURL = "https://api.replicate.com/v1/models"
+
 type Service struct {
-	HTTPClient     *http.Client
-	PromptTemplate *promptgen.PromptTemplate
-	ModelURL       string
-	APIKey         string
+	HTTPClient *http.Client
+	// Model is either "owner/name" or a full predictions URL.
+	Model  string
+	APIKey string
+	Stream bool
 }
 
-var _ llm.Service = &Service{}
+var _ llm.Provider = &Service{}
 
-func NewReplicateService(client *http.Client, promptTemplate *promptgen.PromptTemplate, modelURL string, apiKey string) *Service {
+func NewReplicateService(client *http.Client, model string, apiKey string, stream bool) *Service {
 	return &Service{
-		HTTPClient:     client,
-		PromptTemplate: promptTemplate,
-		ModelURL:       modelURL,
-		APIKey:         apiKey,
+		HTTPClient: client,
+		Model:      model,
+		APIKey:     apiKey,
+		Stream:     stream,
 	}
 }
 
-func (s *Service) GetForecast(mainArticle newsapi.Article, relatedArticles []newsapi.Article, event *polymarket.Event) (*dto.Forecast, error) {
-	if mainArticle.Title == "" || mainArticle.Description == "" {
-		return nil, fmt.Errorf("main article is missing title or description")
-	}
-
-	var (
-		prompt string
-		err    error
-	)
-
-	if event != nil {
-		prompt, err = s.PromptTemplate.CreatePrompt(promptgen.GenerateMarketForecast, struct {
-			MainArticle     newsapi.Article
-			RelatedArticles []newsapi.Article
-			Event           polymarket.Event
-		}{
-			MainArticle:     mainArticle,
-			RelatedArticles: relatedArticles,
-			Event:           *event,
-		})
-	} else {
-		prompt, err = s.PromptTemplate.CreatePrompt(promptgen.GenerateNewsForecast, struct {
-			MainArticle     newsapi.Article
-			RelatedArticles []newsapi.Article
-		}{
-			MainArticle:     mainArticle,
-			RelatedArticles: relatedArticles,
-		})
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("error creating forecast prompt: %v", err)
-	}
-
-	output, err := s.processRequest(prompt, 1024)
-	if err != nil {
-		return nil, err
-	}
-
-	var result dto.Forecast
-	if err := json.Unmarshal([]byte(output), &result); err != nil {
-		return nil, fmt.Errorf("error parsing forecast output: %v\nOutput Data:\n%s", err, output)
-	}
-
-	return &result, nil
+func (s *Service) ModelName() string {
+	return s.Model
 }
 
-func (s *Service) SelectIndexes(templateType promptgen.TemplateType, data interface{}, minSelection int) ([]int, error) {
-	prompt, err := s.PromptTemplate.CreatePrompt(templateType, data)
-	if err != nil {
-		return nil, fmt.Errorf("error creating prompt for %s: %v", templateType, err)
-	}
-
-	outputStr, err := s.processRequest(prompt, 100)
-	if err != nil {
-		return nil, err
-	}
-
-	var selection struct {
-		Selected []int `json:"selected"`
-	}
-	if err := json.Unmarshal([]byte(outputStr), &selection); err != nil {
-		return nil, fmt.Errorf("error parsing selection output: %v\nOutput Data:\n%s", err, outputStr)
-	}
-
-	if len(selection.Selected) < minSelection {
-		return nil, fmt.Errorf("expected at least %d selected items, got %d: %v", minSelection, len(selection.Selected), selection.Selected)
-	}
-
-	return selection.Selected, nil
+func (s *Service) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	return s.processRequest(ctx, req)
 }
 
-func (s *Service) SelectIndex(templateType promptgen.TemplateType, data interface{}) (int, error) {
-	prompt, err := s.PromptTemplate.CreatePrompt(templateType, data)
-	if err != nil {
-		return -1, fmt.Errorf("error creating prompt for %s: %v", templateType, err)
+func predictionsURL(model string) string {
+	if strings.HasPrefix(model, "http://") || strings.HasPrefix(model, "https://") {
+		return model
 	}
-
-	outputStr, err := s.processRequest(prompt, 100)
-	if err != nil {
-		return -1, err
-	}
-
-	var selection struct {
-		Selected int `json:"selected"`
-	}
-	if err := json.Unmarshal([]byte(outputStr), &selection); err != nil {
-		return -1, fmt.Errorf("error parsing single selection output: %v\nOutput Data:\n%s", err, outputStr)
-	}
-
-	return selection.Selected, nil
+	return fmt.Sprintf("%s/%s/predictions", modelsURL, model)
 }
 
-func (s *Service) ExtractKeywords(article newsapi.Article) ([]string, error) {
-	prompt, err := s.PromptTemplate.CreatePrompt(promptgen.ExtractKeywords, article)
-	if err != nil {
-		return nil, fmt.Errorf("error creating keyword extraction prompt: %v", err)
-	}
-
-	outputStr, err := s.processRequest(prompt, 50)
-	if err != nil {
-		return nil, err
-	}
-
-	keywords := strings.Split(strings.TrimSpace(outputStr), ",")
-	if len(keywords) != 2 {
-		return nil, fmt.Errorf("expected 2 keywords, got %d: %v", len(keywords), keywords)
-	}
-
-	for i := range keywords {
-		keywords[i] = strings.TrimSpace(keywords[i])
-	}
-
-	return keywords, nil
-}
-
-func (s *Service) processRequest(prompt string, maxTokens int) (string, error) {
+func (s *Service) processRequest(ctx context.Context, r llm.Request) (*llm.Response, error) {
+	prompt := r.Prompt
 	if len(prompt) == 0 {
-		return "", fmt.Errorf("empty prompt provided")
+		return nil, fmt.Errorf("empty prompt provided")
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, predictionTimeout)
+	defer cancel()
+
 	// Construct the payload for the request
 	payload := RequestPayload{
-		Stream: false,
+		Stream: s.Stream,
 		Input: Input{
-			Prompt:    prompt,
-			MaxTokens: maxTokens,
+			Prompt:      prompt,
+			MaxTokens:   r.MaxTokens,
+			Temperature: r.Temperature,
 		},
 	}
 
 	reqBody, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling request body: %v", err)
+		return nil, fmt.Errorf("error marshaling request body: %v", err)
 	}
 
 	// Make the POST request
-	req, err := http.NewRequest("POST", s.ModelURL, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", predictionsURL(llm.ModelFor(s, r)), bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -179,51 +93,65 @@ func (s *Service) processRequest(prompt string, maxTokens int) (string, error) {
 
 	resp, err := s.HTTPClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
 	}
 
 	// Decode the initial response to get the forecast status and URLs
 	var forecast ResponsePayload
 	err = json.NewDecoder(resp.Body).Decode(&forecast)
 	if err != nil {
-		return "", fmt.Errorf("error parsing response JSON: %v", err)
+		return nil, fmt.Errorf("error parsing response JSON: %v", err)
 	}
 
-	// Poll for completion if needed
-	for forecast.Status != "succeeded" && forecast.Status != "failed" {
-		time.Sleep(2 * time.Second)
+	// From here on the prediction runs remotely, so make sure it is stopped
+	// if we give up on it
+	defer func() {
+		if ctx.Err() != nil && forecast.URLs.Cancel != "" {
+			s.cancelPrediction(forecast.URLs.Cancel)
+		}
+	}()
 
-		// Poll the status using the "get" URL
-		req, err = http.NewRequest("GET", forecast.URLs.Get, nil)
+	// Consume the event stream instead of polling when the model supports it
+	if s.Stream && forecast.URLs.Stream != "" {
+		output, err := s.readStream(ctx, forecast.URLs.Stream)
 		if err != nil {
-			return "", fmt.Errorf("error creating GET request: %v", err)
+			return nil, err
 		}
-		req.Header.Set("Authorization", "Bearer "+s.APIKey)
 
-		resp, err = s.HTTPClient.Do(req)
-		if err != nil {
-			return "", fmt.Errorf("error making GET request: %v", err)
+		// The stream carries no token counts, the finished prediction does.
+		// Without them the usage is estimated from the text.
+		result := &llm.Response{Output: output}
+		if err := s.getPrediction(ctx, forecast.URLs.Get, &forecast); err != nil {
+			log.Printf("Error reading metrics of prediction %s: %v", forecast.ID, err)
+		} else {
+			result.Usage = predictionUsage(forecast)
 		}
-		defer resp.Body.Close()
+		return result, nil
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return "", fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	// Poll for completion if needed
+	for !isTerminal(forecast.Status) {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("prediction %s did not complete: %w", forecast.ID, ctx.Err())
+		case <-time.After(pollInterval):
 		}
 
-		// Update the forecast with the new status
-		err = json.NewDecoder(resp.Body).Decode(&forecast)
-		if err != nil {
-			return "", fmt.Errorf("error parsing forecast JSON: %v", err)
+		if err = s.getPrediction(ctx, forecast.URLs.Get, &forecast); err != nil {
+			return nil, err
 		}
 	}
 
+	if forecast.Status != "succeeded" {
+		return nil, fmt.Errorf("prediction %s %s: %v", forecast.ID, forecast.Status, forecast.Error)
+	}
+
 	// Handle the output
 	var outputStr string
 	switch v := forecast.Output.(type) {
@@ -236,13 +164,151 @@ func (s *Service) processRequest(prompt string, maxTokens int) (string, error) {
 			if str, ok := item.(string); ok {
 				builder.WriteString(str)
 			} else {
-				return "", fmt.Errorf("forecast output array contains non-string elements")
+				return nil, fmt.Errorf("forecast output array contains non-string elements")
 			}
 		}
 		outputStr = builder.String()
 	default:
-		return "", fmt.Errorf("unexpected type for forecast output: %T", forecast.Output)
+		return nil, fmt.Errorf("unexpected type for forecast output: %T", forecast.Output)
+	}
+
+	return &llm.Response{Output: strings.TrimSpace(outputStr), Usage: predictionUsage(forecast)}, nil
+}
+
+// predictionUsage returns the token counts of a prediction, or nil when
+// Replicate reported none.
+func predictionUsage(forecast ResponsePayload) *llm.Usage {
+	if forecast.Metrics.InputTokenCount == 0 && forecast.Metrics.OutputTokenCount == 0 {
+		return nil
+	}
+	return &llm.Usage{
+		PromptTokens:     forecast.Metrics.InputTokenCount,
+		CompletionTokens: forecast.Metrics.OutputTokenCount,
+	}
+}
+
+// readStream consumes the server-sent events of a prediction and returns the
+// assembled output once the "done" event arrives.
+func (s *Service) readStream(ctx context.Context, streamURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", streamURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating stream request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-store")
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	// The stream outlives the regular client timeout on long generations;
+	// it is bounded by the prediction deadline in ctx instead.
+	client := *s.HTTPClient
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error opening prediction stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	}
+
+	var (
+		output strings.Builder
+		event  string
+		data   []string
+	)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line terminates the current event
+		if line == "" {
+			payload := strings.Join(data, "\n")
+			switch event {
+			case "output", "":
+				output.WriteString(payload)
+			case "error":
+				return "", fmt.Errorf("prediction stream failed: %s", payload)
+			case "done":
+				return strings.TrimSpace(output.String()), nil
+			}
+			event, data = "", nil
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, ":"):
+			// Comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(line, "data:")
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading prediction stream: %w", err)
 	}
 
-	return strings.TrimSpace(outputStr), nil
+	return "", fmt.Errorf("prediction stream ended before completion")
+}
+
+func (s *Service) getPrediction(ctx context.Context, getURL string, forecast *ResponsePayload) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating GET request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making GET request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d\nResponse Body:\n%s", resp.StatusCode, string(body))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(forecast); err != nil {
+		return fmt.Errorf("error parsing forecast JSON: %v", err)
+	}
+
+	return nil
+}
+
+// cancelPrediction stops a prediction we are no longer waiting for, so it
+// doesn't keep running (and billing) on Replicate's side.
+func (s *Service) cancelPrediction(cancelURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cancelURL, nil)
+	if err != nil {
+		log.Printf("Error creating cancel request: %v", err)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		log.Printf("Error cancelling prediction: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Cancelling prediction returned status: %s", resp.Status)
+	}
+}
+
+func isTerminal(status string) bool {
+	return status == "succeeded" || status == "failed" || status == "canceled"
 }
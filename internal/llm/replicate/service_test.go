@@ -0,0 +1,185 @@
+package replicate
+
+import (
+	"context"
+	"fmt"
+	"github.com/qoentz/evedict/internal/llm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "output events",
+			events: "event: output\ndata: {\"headline\":\n\nevent: output\ndata:  \"Fed holds\"}\n\nevent: done\ndata: {}\n\n",
+			want:   `{"headline": "Fed holds"}`,
+		},
+		{
+			name:   "multi-line data",
+			events: "event: output\ndata: first\ndata: second\n\nevent: done\ndata: {}\n\n",
+			want:   "first\nsecond",
+		},
+		{
+			name:   "comments and unnamed events",
+			events: ": keep-alive\n\ndata: plain\n\nevent: done\n\n",
+			want:   "plain",
+		},
+		{
+			name:   "stops at done",
+			events: "event: output\ndata: kept\n\nevent: done\ndata: {}\n\nevent: output\ndata: ignored\n\n",
+			want:   "kept",
+		},
+		{
+			name:    "error event",
+			events:  "event: output\ndata: partial\n\nevent: error\ndata: {\"detail\": \"out of memory\"}\n\n",
+			wantErr: "out of memory",
+		},
+		{
+			name:    "cut off",
+			events:  "event: output\ndata: partial\n\nevent: output\ndata: more",
+			wantErr: "ended before completion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept") != "text/event-stream" {
+					t.Errorf("Accept = %q", r.Header.Get("Accept"))
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(tt.events))
+			}))
+			defer server.Close()
+
+			s := NewReplicateService(server.Client(), "", "key", true)
+			got, err := s.readStream(context.Background(), server.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readStream() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readStream() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readStream() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompleteCancelsPrediction(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{"polling", false},
+		{"streaming", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelled := make(chan struct{}, 1)
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			mux.HandleFunc("/predictions", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"id": "p1", "status": "starting", "urls": {"get": "%[1]s/get", "cancel": "%[1]s/cancel", "stream": "%[1]s/stream"}}`, server.URL)
+			})
+			mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(": waiting\n\n"))
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			})
+			mux.HandleFunc("/cancel", func(w http.ResponseWriter, r *http.Request) {
+				cancelled <- struct{}{}
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			s := NewReplicateService(server.Client(), server.URL+"/predictions", "key", tt.stream)
+			start := time.Now()
+			if _, err := s.Complete(ctx, llm.Request{Prompt: "Forecast"}); err == nil {
+				t.Fatal("Complete() returned no error after the deadline")
+			}
+			if elapsed := time.Since(start); elapsed > pollInterval {
+				t.Errorf("Complete() returned after %s, want it to stop at the deadline", elapsed)
+			}
+
+			select {
+			case <-cancelled:
+			default:
+				t.Error("the prediction was not cancelled")
+			}
+		})
+	}
+}
+
+func TestCompleteUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  bool
+		metrics string
+		want    *llm.Usage
+	}{
+		{"polling", false, `{"input_token_count": 120, "output_token_count": 30}`, &llm.Usage{PromptTokens: 120, CompletionTokens: 30}},
+		{"streaming", true, `{"input_token_count": 120, "output_token_count": 30}`, &llm.Usage{PromptTokens: 120, CompletionTokens: 30}},
+		{"not reported", true, `{}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			prediction := func(status string) string {
+				return fmt.Sprintf(`{"id": "p1", "status": %q, "output": ["Fed ", "holds"], "metrics": %s, "urls": {"get": "%[3]s/get", "stream": "%[3]s/stream"}}`, status, tt.metrics, server.URL)
+			}
+
+			mux.HandleFunc("/predictions", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				if tt.stream {
+					fmt.Fprint(w, prediction("starting"))
+				} else {
+					fmt.Fprint(w, prediction("succeeded"))
+				}
+			})
+			mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte("event: output\ndata: Fed holds\n\nevent: done\ndata: {}\n\n"))
+			})
+			mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, prediction("succeeded"))
+			})
+
+			s := NewReplicateService(server.Client(), server.URL+"/predictions", "key", tt.stream)
+			resp, err := s.Complete(context.Background(), llm.Request{Prompt: "Forecast"})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			if resp.Output != "Fed holds" {
+				t.Errorf("Output = %q", resp.Output)
+			}
+			if (resp.Usage == nil) != (tt.want == nil) || (resp.Usage != nil && *resp.Usage != *tt.want) {
+				t.Errorf("Usage = %+v, want %+v", resp.Usage, tt.want)
+			}
+		})
+	}
+}
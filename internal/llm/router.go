@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/promptgen"
+)
+
+// Route says which provider and model settings answer a template type.
+// Zero values fall back to the provider's own defaults.
+type Route struct {
+	Provider    Provider
+	Model       string
+	MaxTokens   int
+	Temperature *float64
+	// ContextWindow overrides the context size known for the model.
+	ContextWindow int
+}
+
+// Router is a Provider that dispatches each request to the route configured
+// for its template type, or to the default route.
+type Router struct {
+	Default Route
+	Routes  map[promptgen.TemplateType]Route
+}
+
+var (
+	_ Provider     = &Router{}
+	_ PromptBudget = &Router{}
+)
+
+func NewRouter(defaultRoute Route, routes map[promptgen.TemplateType]Route) *Router {
+	if routes == nil {
+		routes = map[promptgen.TemplateType]Route{}
+	}
+
+	return &Router{
+		Default: defaultRoute,
+		Routes:  routes,
+	}
+}
+
+// DefaultMaxTokens is the output budget for a template type when its route
+// doesn't set one.
+func DefaultMaxTokens(templateType promptgen.TemplateType) int {
+	switch templateType {
+	case promptgen.GenerateNewsForecast, promptgen.GenerateMarketForecast, promptgen.TranslateArticle:
+		return 1024
+	case promptgen.ExtractKeywords:
+		return 50
+	default:
+		return 100
+	}
+}
+
+func (r *Router) Route(templateType promptgen.TemplateType) Route {
+	if route, ok := r.Routes[templateType]; ok {
+		return route
+	}
+	return r.Default
+}
+
+// PromptBudget is the context window of the model the template type is routed
+// to, less the room reserved for its output.
+func (r *Router) PromptBudget(templateType promptgen.TemplateType) int {
+	route := r.Route(templateType)
+
+	window := route.ContextWindow
+	if window == 0 {
+		window = ContextWindowFor(ModelFor(route.Provider, Request{Model: route.Model}))
+	}
+
+	maxTokens := route.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = DefaultMaxTokens(templateType)
+	}
+
+	return window - maxTokens
+}
+
+func (r *Router) ModelName() string {
+	return ModelFor(r.Default.Provider, Request{Model: r.Default.Model})
+}
+
+func (r *Router) Complete(ctx context.Context, req Request) (*Response, error) {
+	route := r.Route(req.TemplateType)
+
+	if req.Model == "" {
+		req.Model = route.Model
+	}
+
+	if req.MaxTokens == 0 {
+		req.MaxTokens = route.MaxTokens
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = DefaultMaxTokens(req.TemplateType)
+	}
+
+	if req.Temperature == nil {
+		req.Temperature = route.Temperature
+	}
+
+	resp, err := route.Provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Model == "" {
+		resp.Model = ModelFor(route.Provider, req)
+	}
+
+	return resp, nil
+}
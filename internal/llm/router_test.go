@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"testing"
+)
+
+// recordingProvider answers with its name and keeps the requests it got.
+type recordingProvider struct {
+	name     string
+	requests []Request
+}
+
+func (p *recordingProvider) Complete(_ context.Context, req Request) (*Response, error) {
+	p.requests = append(p.requests, req)
+	return &Response{Output: p.name}, nil
+}
+
+func (p *recordingProvider) ModelName() string {
+	return p.name + "-model"
+}
+
+func TestRouter(t *testing.T) {
+	zero := 0.0
+	warm := 0.7
+
+	replicate := &recordingProvider{name: "replicate"}
+	openai := &recordingProvider{name: "openai"}
+
+	r := NewRouter(Route{Provider: replicate, Temperature: &warm}, map[promptgen.TemplateType]Route{
+		promptgen.SelectArticles:  {Provider: openai, Model: "gpt-4o-mini", MaxTokens: 200, Temperature: &zero},
+		promptgen.ExtractKeywords: {Provider: replicate, Model: "meta/meta-llama-3-8b-instruct"},
+	})
+
+	tests := []struct {
+		name            string
+		req             Request
+		wantProvider    string
+		wantModel       string
+		wantMaxTokens   int
+		wantTemperature *float64
+	}{
+		{
+			name:            "routed template",
+			req:             Request{TemplateType: promptgen.SelectArticles},
+			wantProvider:    "openai",
+			wantModel:       "gpt-4o-mini",
+			wantMaxTokens:   200,
+			wantTemperature: &zero,
+		},
+		{
+			name:          "routed to the default provider with another model",
+			req:           Request{TemplateType: promptgen.ExtractKeywords},
+			wantProvider:  "replicate",
+			wantModel:     "meta/meta-llama-3-8b-instruct",
+			wantMaxTokens: DefaultMaxTokens(promptgen.ExtractKeywords),
+		},
+		{
+			name:            "falls back to the default route",
+			req:             Request{TemplateType: promptgen.GenerateNewsForecast},
+			wantProvider:    "replicate",
+			wantMaxTokens:   DefaultMaxTokens(promptgen.GenerateNewsForecast),
+			wantTemperature: &warm,
+		},
+		{
+			name:            "request settings win",
+			req:             Request{TemplateType: promptgen.SelectArticles, Model: "gpt-4o", MaxTokens: 50, Temperature: &warm},
+			wantProvider:    "openai",
+			wantModel:       "gpt-4o",
+			wantMaxTokens:   50,
+			wantTemperature: &warm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := r.Complete(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Output != tt.wantProvider {
+				t.Fatalf("answered by %s, want %s", resp.Output, tt.wantProvider)
+			}
+
+			provider := replicate
+			if tt.wantProvider == "openai" {
+				provider = openai
+			}
+			got := provider.requests[len(provider.requests)-1]
+			if got.Model != tt.wantModel || got.MaxTokens != tt.wantMaxTokens || got.Temperature != tt.wantTemperature {
+				t.Errorf("sent model %q, %d max tokens and temperature %v, want %q, %d and %v", got.Model, got.MaxTokens, got.Temperature, tt.wantModel, tt.wantMaxTokens, tt.wantTemperature)
+			}
+		})
+	}
+
+	if got := r.ModelName(); got != "replicate-model" {
+		t.Errorf("ModelName() = %q, want the default provider's model", got)
+	}
+	if got := ModelFor(r.Route(promptgen.SelectArticles).Provider, Request{}); got != "openai-model" {
+		t.Errorf("ModelFor() = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"sync"
+)
+
+type runIDKey struct{}
+
+// WithRunID tags every model call made with ctx as part of one generation run.
+func WithRunID(ctx context.Context, runID uuid.UUID) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+func RunIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	runID, ok := ctx.Value(runIDKey{}).(uuid.UUID)
+	return runID, ok
+}
+
+type sampleKey struct{}
+
+// WithSample marks the model calls made with ctx as the nth sample of a
+// repeated generation.
+func WithSample(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, sampleKey{}, n)
+}
+
+func SampleFromContext(ctx context.Context) int {
+	n, _ := ctx.Value(sampleKey{}).(int)
+	return n
+}
+
+type variantsKey struct{}
+
+// variants holds the prompt variant each template type was rendered with.
+type variants struct {
+	mu     sync.Mutex
+	chosen map[promptgen.TemplateType]promptgen.TemplateType
+}
+
+// WithVariants makes every prompt rendered with ctx reuse the variant first
+// picked for its template type, so the samples and ensemble members of one
+// forecast all answer the same prompt.
+func WithVariants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, variantsKey{}, &variants{chosen: map[promptgen.TemplateType]promptgen.TemplateType{}})
+}
+
+// pickVariant returns the variant of templateType to render, picking one by
+// weight unless ctx already chose one that is still loaded.
+func pickVariant(ctx context.Context, promptTemplate *promptgen.PromptTemplate, templateType promptgen.TemplateType) promptgen.TemplateType {
+	v, ok := ctx.Value(variantsKey{}).(*variants)
+	if !ok {
+		return promptTemplate.Pick(templateType)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if name, ok := v.chosen[templateType]; ok && promptTemplate.Has(name) {
+		return name
+	}
+
+	name := promptTemplate.Pick(templateType)
+	v.chosen[templateType] = name
+	return name
+}
@@ -1,15 +1,18 @@
 package llm
 
 import (
+	"context"
 	"github.com/qoentz/evedict/internal/api/dto"
-	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
-	"github.com/qoentz/evedict/internal/eventfeed/polymarket"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"github.com/qoentz/evedict/internal/promptgen"
 )
 
 type Service interface {
-	GetForecast(mainArticle newsapi.Article, relatedArticles []newsapi.Article, event *polymarket.Event) (*dto.Forecast, error)
-	SelectIndexes(templateType promptgen.TemplateType, data interface{}, minSelection int) ([]int, error)
-	SelectIndex(templateType promptgen.TemplateType, data interface{}) (int, error)
-	ExtractKeywords(article newsapi.Article) ([]string, error)
+	GetForecast(ctx context.Context, mainArticle eventfeed.Article, relatedArticles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error)
+	SelectIndexes(ctx context.Context, templateType promptgen.TemplateType, data interface{}, minSelection int) ([]int, error)
+	SelectIndex(ctx context.Context, templateType promptgen.TemplateType, data interface{}) (int, error)
+	ExtractKeywords(ctx context.Context, article eventfeed.Article) ([]string, error)
+	// TranslateArticle translates the title, description and content of an
+	// article written in language into English.
+	TranslateArticle(ctx context.Context, article eventfeed.Article, language string) (*eventfeed.Article, error)
 }
@@ -0,0 +1,44 @@
+package stub
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/llm"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// embeddingDimensions is the size of the stub vectors.
+const embeddingDimensions = 256
+
+var _ llm.Embedder = &Service{}
+
+func (s *Service) ModelName() string {
+	return "stub"
+}
+
+// Embed hashes the words of each text into a fixed-size vector, so texts that
+// share words come out similar and the same text always gets the same vector.
+func (s *Service) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		vector := make([]float32, embeddingDimensions)
+
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			if len(w) < 3 {
+				continue
+			}
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(w))
+			vector[h.Sum32()%embeddingDimensions]++
+		}
+
+		vectors[i] = vector
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,214 @@
+package stub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	forecastFixture = "forecast.json"
+	keywordFixture  = "keywords.json"
+)
+
+// Service answers every prompt template without contacting a model. Answers
+// come from fixture files when present and from a seeded random source
+// otherwise, so runs are repeatable for a given seed. As a Provider it goes
+// through the same routing, caching and usage recording as the real ones.
+type Service struct {
+	forecasts []dto.Forecast
+	keywords  [][]string
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+var _ llm.Provider = &Service{}
+
+// NewStubService loads the optional fixtures in fixtureDir. forecast.json may
+// hold one forecast or a list of them, keywords.json a list of keyword pairs.
+func NewStubService(fixtureDir string, seed int64) (*Service, error) {
+	s := &Service{rng: rand.New(rand.NewSource(seed))}
+
+	if fixtureDir == "" {
+		return s, nil
+	}
+
+	if err := loadFixture(filepath.Join(fixtureDir, forecastFixture), &s.forecasts); err != nil {
+		return nil, err
+	}
+
+	for i := range s.forecasts {
+		if err := llm.ValidateForecast(&s.forecasts[i]); err != nil {
+			return nil, fmt.Errorf("invalid forecast fixture %d: %v", i, err)
+		}
+	}
+
+	if err := loadFixture(filepath.Join(fixtureDir, keywordFixture), &s.keywords); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Service) Complete(_ context.Context, req llm.Request) (*llm.Response, error) {
+	var output interface{}
+	var err error
+
+	switch req.TemplateType {
+	case promptgen.GenerateNewsForecast, promptgen.GenerateMarketForecast:
+		output = s.forecast()
+	case promptgen.SelectArticles, promptgen.SelectMarkets:
+		// The prompt doesn't tell how many items there are, but every
+		// selection asks for at least two
+		output = map[string][]int{"selected": {0, 1}}
+	case promptgen.SelectArticleForEvent:
+		output = map[string]int{"selected": 0}
+	case promptgen.ExtractKeywords:
+		var keywords []string
+		keywords, err = s.extractKeywords(req.Prompt)
+		if err != nil {
+			return nil, err
+		}
+		return &llm.Response{Output: strings.Join(keywords, ", "), Model: s.ModelName()}, nil
+	case promptgen.TranslateArticle:
+		output, err = translate(req.Prompt)
+	default:
+		return nil, fmt.Errorf("stub has no answer for %s", req.TemplateType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding stub answer for %s: %v", req.TemplateType, err)
+	}
+
+	return &llm.Response{Output: string(b), Model: s.ModelName()}, nil
+}
+
+// forecast picks a fixture or makes up a forecast. The prompt doesn't tell
+// how many related articles there are, so no source is cited.
+func (s *Service) forecast() dto.Forecast {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.forecasts) > 0 {
+		forecast := s.forecasts[s.rng.Intn(len(s.forecasts))]
+		forecast.Outcomes = append([]dto.Outcome(nil), forecast.Outcomes...)
+		for i := range forecast.Outcomes {
+			forecast.Outcomes[i].SourceIndexes = nil
+		}
+		return forecast
+	}
+
+	outcomes := []dto.Outcome{
+		{Content: "The situation escalates further within the coming weeks."},
+		{Content: "Developments stall and the status quo holds."},
+		{Content: "A negotiated or institutional resolution is reached."},
+	}
+	for i := range outcomes {
+		outcomes[i].ConfidenceLevel = 10 + s.rng.Intn(81)
+		outcomes[i].Rationale = fmt.Sprintf("Stub rationale for a %d%% confidence level.", outcomes[i].ConfidenceLevel)
+	}
+
+	return dto.Forecast{
+		Headline: "What comes next",
+		Summary:  "Stub forecast made without a model.",
+		Outcomes: outcomes,
+	}
+}
+
+// extractKeywords uses the two longest distinct words of the fenced article
+// text, or of the whole prompt without any, as a stand-in for the model's
+// keyword choice.
+func (s *Service) extractKeywords(prompt string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.keywords) > 0 {
+		return append([]string(nil), s.keywords[s.rng.Intn(len(s.keywords))]...), nil
+	}
+
+	text := strings.Join(promptgen.Unfence(prompt), "\n")
+	if text == "" {
+		text = prompt
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range strings.Fields(text) {
+		w = strings.Trim(w, ".,:;!?\"'()[]")
+		if len(w) < 3 || seen[strings.ToLower(w)] {
+			continue
+		}
+		seen[strings.ToLower(w)] = true
+		words = append(words, w)
+	}
+
+	if len(words) < 2 {
+		return nil, fmt.Errorf("expected 2 keywords, got %d: %v", len(words), words)
+	}
+
+	sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	return words[:2], nil
+}
+
+// translate leaves the text as it is, so non-English articles still flow
+// through the pipeline. It reads the fields back from the "Title:",
+// "Description:" and "Content:" lines of the built-in translation prompt.
+func translate(prompt string) (map[string]string, error) {
+	fields := map[string]string{}
+	for _, name := range []string{"Title", "Description", "Content"} {
+		_, value, ok := strings.Cut(prompt, "\n"+name+": ")
+		if !ok {
+			continue
+		}
+
+		// The description and content are fenced and may span lines
+		if texts := promptgen.Unfence(value); len(texts) > 0 && strings.HasPrefix(value, "<") {
+			fields[strings.ToLower(name)] = texts[0]
+			continue
+		}
+		value, _, _ = strings.Cut(value, "\n")
+		fields[strings.ToLower(name)] = value
+	}
+
+	if strings.TrimSpace(fields["title"]) == "" {
+		return nil, fmt.Errorf("stub found no title in the translation prompt")
+	}
+	return fields, nil
+}
+
+func loadFixture(path string, target interface{}) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error reading fixture %s: %v", path, err)
+	}
+
+	// Accept a single object as well as a list.
+	trimmed := strings.TrimSpace(string(b))
+	if strings.HasPrefix(trimmed, "{") {
+		trimmed = "[" + trimmed + "]"
+	}
+
+	if err := json.Unmarshal([]byte(trimmed), target); err != nil {
+		return fmt.Errorf("error parsing fixture %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+const English = "en"
+
+// Names maps the ISO 639-1 codes DetectLanguage returns to the language names
+// used in the translation prompt.
+var Names = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"sv": "Swedish",
+	"pl": "Polish",
+	"tr": "Turkish",
+	"ru": "Russian",
+	"uk": "Ukrainian",
+	"el": "Greek",
+	"ar": "Arabic",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+}
+
+// stopWords are frequent short words that tell the Latin-script languages
+// apart.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "for", "on", "that", "with", "as", "by", "was", "it", "from", "at", "has", "are", "after"},
+	"es": {"el", "la", "los", "las", "de", "del", "que", "y", "en", "un", "una", "por", "con", "para", "es", "se", "al"},
+	"fr": {"le", "la", "les", "des", "de", "du", "et", "un", "une", "est", "que", "pour", "dans", "sur", "au", "aux", "pas"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "den", "dem", "von", "zu", "auf", "für", "im", "sich"},
+	"it": {"il", "la", "di", "che", "e", "un", "una", "per", "con", "del", "della", "gli", "nel", "sono", "non", "le"},
+	"pt": {"o", "a", "os", "as", "de", "do", "da", "que", "e", "em", "um", "uma", "para", "com", "não", "no", "na"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "op", "te", "niet", "met", "voor", "zijn", "bij", "ook"},
+	"sv": {"och", "att", "det", "som", "en", "på", "är", "av", "för", "med", "till", "den", "inte", "har"},
+	"pl": {"i", "w", "na", "się", "z", "że", "do", "nie", "jest", "to", "od", "po", "dla", "przez"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "ile", "olarak", "çok", "daha", "gibi", "olan"},
+}
+
+// DetectLanguage guesses the ISO 639-1 code of text from its script, or for
+// Latin script from the stop words it contains. It returns "" when the text
+// gives too little to go on.
+func DetectLanguage(text string) string {
+	if code := detectScript(text); code != "" {
+		return code
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	counts := map[string]int{}
+	for _, w := range words {
+		for code, list := range stopWords {
+			for _, sw := range list {
+				if w == sw {
+					counts[code]++
+				}
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for code, n := range counts {
+		// Prefer English on ties, it's what most feeds deliver
+		if n > bestCount || (n == bestCount && code == English) {
+			best, bestCount = code, n
+		}
+	}
+
+	if bestCount < 2 {
+		return ""
+	}
+	return best
+}
+
+// detectScript recognizes the languages with a script of their own. Text
+// where fewer than a third of the letters are in one of these scripts is left
+// to the stop word check.
+func detectScript(text string) string {
+	var letters int
+	counts := map[string]int{}
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			// Letters only Ukrainian uses
+			if strings.ContainsRune("їієґЇІЄҐ", r) {
+				counts["uk"] += 10
+			}
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		}
+	}
+
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		counts["zh"] = 0
+	}
+	if counts["uk"] > 0 {
+		counts["uk"] += counts["ru"]
+		counts["ru"] = 0
+	}
+
+	best, bestCount := "", 0
+	for code, n := range counts {
+		if n > bestCount {
+			best, bestCount = code, n
+		}
+	}
+
+	if bestCount*3 < letters {
+		return ""
+	}
+	return best
+}
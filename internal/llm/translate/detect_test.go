@@ -0,0 +1,43 @@
+package translate
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"English", "The central bank held rates steady after the meeting on Wednesday", "en"},
+		{"Spanish", "El banco central mantiene los tipos de interés por la inflación", "es"},
+		{"French", "La banque centrale maintient les taux pour la deuxième fois dans une année", "fr"},
+		{"German", "Die Zentralbank lässt den Leitzins unverändert und will die Inflation mit Geduld bekämpfen", "de"},
+		{"Russian", "Центральный банк сохранил ключевую ставку", "ru"},
+		{"Ukrainian", "Національний банк зберіг облікову ставку", "uk"},
+		{"Japanese", "日本銀行は金利を据え置いた", "ja"},
+		{"Chinese", "中国人民银行维持利率不变", "zh"},
+		{"Korean", "한국은행이 기준금리를 동결했다", "ko"},
+		{"Greek", "Η κεντρική τράπεζα διατήρησε τα επιτόκια", "el"},
+		{"Arabic", "أبقى البنك المركزي أسعار الفائدة دون تغيير", "ar"},
+		{"mostly Latin with a foreign name", "The Bank of Japan, or 日銀, held rates steady in the review on Tuesday", "en"},
+		{"too short", "Fed holds", ""},
+		{"no letters", "2025 - 42%", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageNames(t *testing.T) {
+	for code := range stopWords {
+		if _, ok := Names[code]; !ok {
+			t.Errorf("no name for detected language %q", code)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package translate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"sync"
+)
+
+// maxCacheEntries bounds the in-memory cache. It is cleared when full, the
+// response cache of the LLM layer still holds older translations.
+const maxCacheEntries = 1000
+
+// maxConcurrentTranslations bounds the translation calls of one batch.
+const maxConcurrentTranslations = 4
+
+// Translator is the pipeline step that turns articles from any feed into
+// English before they are selected from or used in a forecast.
+type Translator struct {
+	AIService llm.Service
+
+	mu    sync.Mutex
+	cache map[string]eventfeed.Article
+}
+
+func NewTranslator(aiService llm.Service) *Translator {
+	return &Translator{
+		AIService: aiService,
+		cache:     map[string]eventfeed.Article{},
+	}
+}
+
+// TranslateArticles returns the articles in English, in the same order.
+// Articles that can't be translated are kept as they are, so a failed
+// translation never costs the pipeline an article. Up to
+// maxConcurrentTranslations are translated at once.
+func (t *Translator) TranslateArticles(ctx context.Context, articles []eventfeed.Article) []eventfeed.Article {
+	result := make([]eventfeed.Article, len(articles))
+	slots := make(chan struct{}, maxConcurrentTranslations)
+
+	var wg sync.WaitGroup
+	for i, article := range articles {
+		wg.Add(1)
+		go func(i int, article eventfeed.Article) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			translated, err := t.TranslateArticle(ctx, article)
+			if err != nil {
+				log.Printf("Error translating article %q, keeping it untranslated: %v", article.Title, err)
+				result[i] = article
+				return
+			}
+			result[i] = *translated
+		}(i, article)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// TranslateArticle translates a single article, using article.Language when
+// the feed reports one and detecting the language otherwise.
+func (t *Translator) TranslateArticle(ctx context.Context, article eventfeed.Article) (*eventfeed.Article, error) {
+	language := article.Language
+	if language == "" {
+		language = DetectLanguage(article.Title + "\n" + article.Description + "\n" + article.Content)
+	}
+
+	if language == "" || language == English {
+		return &article, nil
+	}
+
+	key := cacheKey(article)
+
+	t.mu.Lock()
+	cached, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		return &cached, nil
+	}
+
+	name, ok := Names[language]
+	if !ok {
+		name = language
+	}
+
+	translated, err := t.AIService.TranslateArticle(ctx, article, name)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	if len(t.cache) >= maxCacheEntries {
+		t.cache = map[string]eventfeed.Article{}
+	}
+	t.cache[key] = *translated
+	t.mu.Unlock()
+
+	return translated, nil
+}
+
+func cacheKey(article eventfeed.Article) string {
+	sum := sha256.Sum256([]byte(article.Title + "\x00" + article.Description + "\x00" + article.Content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTranslator translates by prefixing the title and fails for the titles
+// in failing. It keeps the languages it was asked for and how many calls ran
+// at once.
+type fakeTranslator struct {
+	failing map[string]bool
+
+	mu        sync.Mutex
+	languages []string
+	running   int
+	peak      int
+}
+
+func (f *fakeTranslator) TranslateArticle(_ context.Context, article eventfeed.Article, language string) (*eventfeed.Article, error) {
+	f.mu.Lock()
+	f.languages = append(f.languages, language)
+	f.running++
+	if f.running > f.peak {
+		f.peak = f.running
+	}
+	f.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	f.mu.Lock()
+	f.running--
+	f.mu.Unlock()
+
+	if f.failing[article.Title] {
+		return nil, errors.New("invalid JSON")
+	}
+
+	translated := article
+	translated.Title = "en: " + article.Title
+	translated.Language = "en"
+	return &translated, nil
+}
+
+func (f *fakeTranslator) GetForecast(context.Context, eventfeed.Article, []eventfeed.Article, *eventfeed.Event) (*dto.Forecast, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeTranslator) SelectIndexes(context.Context, promptgen.TemplateType, interface{}, int) ([]int, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeTranslator) SelectIndex(context.Context, promptgen.TemplateType, interface{}) (int, error) {
+	return -1, errors.New("not supported")
+}
+
+func (f *fakeTranslator) ExtractKeywords(context.Context, eventfeed.Article) ([]string, error) {
+	return nil, errors.New("not supported")
+}
+
+func titles(articles []eventfeed.Article) []string {
+	t := make([]string, len(articles))
+	for i, article := range articles {
+		t[i] = article.Title
+	}
+	return t
+}
+
+func TestTranslateArticles(t *testing.T) {
+	f := &fakeTranslator{failing: map[string]bool{"Fehler": true}}
+	translator := NewTranslator(f)
+
+	articles := []eventfeed.Article{
+		{Title: "Zinsen", Language: "de"},
+		{Title: "Fed holds", Language: "en"},
+		{Title: "Fehler", Language: "de"},
+		{Title: "Tipos", Language: "es"},
+		{Title: "Taux", Language: "fr"},
+		{Title: "Zinsen", Language: "de", Description: "Leitzins"},
+		{Title: "The central bank held rates steady after the meeting on Wednesday"},
+	}
+
+	got := translator.TranslateArticles(context.Background(), articles)
+
+	// Failed translations are kept untranslated, in place
+	want := []string{"en: Zinsen", "Fed holds", "Fehler", "en: Tipos", "en: Taux", "en: Zinsen", articles[6].Title}
+	if !reflect.DeepEqual(titles(got), want) {
+		t.Errorf("titles = %q, want %q", titles(got), want)
+	}
+	if got[2].Language != "de" {
+		t.Errorf("untranslated article has language %q, want de", got[2].Language)
+	}
+
+	if len(f.languages) != 5 {
+		t.Errorf("translated %d articles, want the 5 not in English", len(f.languages))
+	}
+	if f.peak < 2 || f.peak > maxConcurrentTranslations {
+		t.Errorf("%d translations ran at once, want 2 to %d", f.peak, maxConcurrentTranslations)
+	}
+
+	// Translations are cached
+	translator.TranslateArticles(context.Background(), articles[:1])
+	if len(f.languages) != 5 {
+		t.Errorf("translated a cached article again")
+	}
+}
+
+func TestTranslateArticleLanguageName(t *testing.T) {
+	f := &fakeTranslator{}
+	translator := NewTranslator(f)
+
+	if _, err := translator.TranslateArticle(context.Background(), eventfeed.Article{Title: "Zinsen", Language: "de"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := translator.TranslateArticle(context.Background(), eventfeed.Article{Title: "Rentes", Language: "xx"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(f.languages, []string{Names["de"], "xx"}) {
+		t.Errorf("asked to translate from %q", f.languages)
+	}
+}
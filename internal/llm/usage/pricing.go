@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is the cost in USD per million tokens.
+type Price struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+type Pricing map[string]Price
+
+// ParsePricing reads "model=input,output;model=input,output". Model names may
+// themselves contain "=" or ":", so the prices are split off the last "=".
+func ParsePricing(s string) (Pricing, error) {
+	pricing := Pricing{}
+
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid pricing entry %q, expected model=input,output", entry)
+		}
+
+		prices := strings.Split(entry[i+1:], ",")
+		if len(prices) != 2 {
+			return nil, fmt.Errorf("invalid pricing entry %q, expected model=input,output", entry)
+		}
+
+		input, err := strconv.ParseFloat(strings.TrimSpace(prices[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input price in %q: %v", entry, err)
+		}
+
+		output, err := strconv.ParseFloat(strings.TrimSpace(prices[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output price in %q: %v", entry, err)
+		}
+
+		pricing[strings.TrimSpace(entry[:i])] = Price{InputPerMillion: input, OutputPerMillion: output}
+	}
+
+	return pricing, nil
+}
+
+// Estimate prices a call. Unknown models cost nothing.
+func (p Pricing) Estimate(model string, promptTokens, completionTokens int) float64 {
+	price, ok := p[model]
+	if !ok {
+		return 0
+	}
+
+	return (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1_000_000
+}
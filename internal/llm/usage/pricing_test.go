@@ -0,0 +1,76 @@
+package usage
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParsePricing(t *testing.T) {
+	tests := []struct {
+		name    string
+		pricing string
+		want    Pricing
+		wantErr bool
+	}{
+		{"empty", "", Pricing{}, false},
+		{
+			name:    "several models",
+			pricing: "gpt-4o-mini=0.15,0.6; meta/meta-llama-3-70b-instruct=0.65,2.75;",
+			want: Pricing{
+				"gpt-4o-mini":                    {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+				"meta/meta-llama-3-70b-instruct": {InputPerMillion: 0.65, OutputPerMillion: 2.75},
+			},
+		},
+		{
+			name:    "model with = and :",
+			pricing: "llama3:8b=q4=0, 0",
+			want:    Pricing{"llama3:8b=q4": {}},
+		},
+		{"missing model", "=1,2", nil, true},
+		{"missing prices", "gpt-4o-mini", nil, true},
+		{"one price", "gpt-4o-mini=0.15", nil, true},
+		{"bad input price", "gpt-4o-mini=free,0.6", nil, true},
+		{"bad output price", "gpt-4o-mini=0.15,free", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePricing(tt.pricing)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePricing() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePricing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	pricing := Pricing{"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.6}}
+
+	tests := []struct {
+		model            string
+		promptTokens     int
+		completionTokens int
+		want             float64
+	}{
+		{"gpt-4o-mini", 1_000_000, 0, 0.15},
+		{"gpt-4o-mini", 0, 1_000_000, 0.6},
+		{"gpt-4o-mini", 2000, 500, 0.0006},
+		{"unknown", 2000, 500, 0},
+	}
+
+	for _, tt := range tests {
+		if got := pricing.Estimate(tt.model, tt.promptTokens, tt.completionTokens); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Estimate(%s, %d, %d) = %g, want %g", tt.model, tt.promptTokens, tt.completionTokens, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/llm"
+	"log"
+	"time"
+)
+
+type Recorder interface {
+	SaveLLMCall(call *model.LLMCall) error
+}
+
+// Provider records the size, latency, outcome and estimated cost of every
+// call made through the wrapped provider.
+type Provider struct {
+	Next     llm.Provider
+	Recorder Recorder
+	Pricing  Pricing
+}
+
+var _ llm.Provider = &Provider{}
+
+func NewRecordingProvider(next llm.Provider, recorder Recorder, pricing Pricing) *Provider {
+	return &Provider{
+		Next:     next,
+		Recorder: recorder,
+		Pricing:  pricing,
+	}
+}
+
+func (p *Provider) ModelName() string {
+	return p.Next.ModelName()
+}
+
+func (p *Provider) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	start := time.Now()
+	resp, err := p.Next.Complete(ctx, req)
+	latency := time.Since(start)
+
+	call := &model.LLMCall{
+		ID:           uuid.New(),
+		TemplateType: string(req.TemplateType),
+		Model:        llm.ModelFor(p.Next, req),
+		PromptChars:  len(req.Prompt),
+		LatencyMs:    latency.Milliseconds(),
+		Status:       "succeeded",
+		CreatedAt:    start.UTC(),
+	}
+
+	if runID, ok := llm.RunIDFromContext(ctx); ok {
+		call.RunID = &runID
+	}
+
+	// Estimated unless the backend reports token counts
+	promptTokens := llm.EstimateTokens(req.Prompt)
+	completionTokens := 0
+
+	if err != nil {
+		msg := err.Error()
+		call.Status = "failed"
+		call.Error = &msg
+	} else {
+		call.OutputChars = len(resp.Output)
+		completionTokens = llm.EstimateTokens(resp.Output)
+
+		if resp.Usage != nil {
+			call.PromptTokens = &resp.Usage.PromptTokens
+			call.CompletionTokens = &resp.Usage.CompletionTokens
+			promptTokens = resp.Usage.PromptTokens
+			completionTokens = resp.Usage.CompletionTokens
+		}
+	}
+
+	call.EstimatedCost = p.Pricing.Estimate(call.Model, promptTokens, completionTokens)
+
+	if saveErr := p.Recorder.SaveLLMCall(call); saveErr != nil {
+		log.Printf("Error recording LLM call: %v", saveErr)
+	}
+
+	return resp, err
+}
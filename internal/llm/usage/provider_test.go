@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/llm"
+	"math"
+	"strings"
+	"testing"
+)
+
+type callLog []*model.LLMCall
+
+func (l *callLog) SaveLLMCall(call *model.LLMCall) error {
+	*l = append(*l, call)
+	return nil
+}
+
+// fixedProvider answers every prompt with resp or err.
+type fixedProvider struct {
+	resp *llm.Response
+	err  error
+}
+
+func (p *fixedProvider) Complete(context.Context, llm.Request) (*llm.Response, error) {
+	return p.resp, p.err
+}
+
+func (p *fixedProvider) ModelName() string {
+	return "gpt-4o-mini"
+}
+
+func TestRecordingProvider(t *testing.T) {
+	pricing := Pricing{"gpt-4o-mini": {InputPerMillion: 1_000_000, OutputPerMillion: 2_000_000}}
+	prompt := strings.Repeat("x", 400)
+
+	tests := []struct {
+		name       string
+		next       *fixedProvider
+		wantStatus string
+		wantCost   float64
+	}{
+		{
+			name:       "reported usage",
+			next:       &fixedProvider{resp: &llm.Response{Output: "fed, rates", Usage: &llm.Usage{PromptTokens: 90, CompletionTokens: 3}}},
+			wantStatus: "succeeded",
+			wantCost:   90 + 2*3,
+		},
+		{
+			name:       "estimated usage",
+			next:       &fixedProvider{resp: &llm.Response{Output: strings.Repeat("y", 40)}},
+			wantStatus: "succeeded",
+			wantCost:   100 + 2*10,
+		},
+		{
+			// Rounded up like the prompt budget, so no answer is free
+			name:       "short output",
+			next:       &fixedProvider{resp: &llm.Response{Output: "yes"}},
+			wantStatus: "succeeded",
+			wantCost:   100 + 2*1,
+		},
+		{
+			name:       "failed call",
+			next:       &fixedProvider{err: errors.New("unavailable")},
+			wantStatus: "failed",
+			wantCost:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &callLog{}
+			p := NewRecordingProvider(tt.next, calls, pricing)
+
+			runID := uuid.New()
+			ctx := llm.WithRunID(context.Background(), runID)
+			if _, err := p.Complete(ctx, llm.Request{TemplateType: "extract_keywords", Prompt: prompt}); err != tt.next.err {
+				t.Fatalf("Complete() error = %v, want %v", err, tt.next.err)
+			}
+
+			if len(*calls) != 1 {
+				t.Fatalf("recorded %d calls, want 1", len(*calls))
+			}
+			call := (*calls)[0]
+			if call.Status != tt.wantStatus || call.Model != "gpt-4o-mini" || call.TemplateType != "extract_keywords" || call.PromptChars != 400 {
+				t.Errorf("recorded %+v", call)
+			}
+			if call.RunID == nil || *call.RunID != runID {
+				t.Errorf("RunID = %v, want %s", call.RunID, runID)
+			}
+			if math.Abs(call.EstimatedCost-tt.wantCost) > 1e-9 {
+				t.Errorf("EstimatedCost = %g, want %g", call.EstimatedCost, tt.wantCost)
+			}
+			if (tt.next.err != nil) != (call.Error != nil) {
+				t.Errorf("Error = %v", call.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxHeadlineLength matches the VARCHAR(255) headline column.
+	MaxHeadlineLength = 255
+	MinConfidence     = 0
+	MaxConfidence     = 100
+)
+
+// ExtractJSON strips markdown fences and any prose around the first JSON
+// object in a model response.
+func ExtractJSON(output string) string {
+	output = strings.TrimSpace(output)
+
+	if strings.HasPrefix(output, "```") {
+		output = strings.TrimPrefix(output, "```")
+		if i := strings.Index(output, "\n"); i >= 0 {
+			// Drop the language hint, e.g. ```json
+			output = output[i+1:]
+		}
+		if i := strings.LastIndex(output, "```"); i >= 0 {
+			output = output[:i]
+		}
+	}
+
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return strings.TrimSpace(output)
+	}
+
+	return output[start : end+1]
+}
+
+// ParseForecast extracts, decodes and validates a forecast from raw model output.
+func ParseForecast(output string) (*dto.Forecast, error) {
+	var result dto.Forecast
+	if err := json.Unmarshal([]byte(ExtractJSON(output)), &result); err != nil {
+		return nil, fmt.Errorf("output is not valid JSON: %v", err)
+	}
+
+	if err := ValidateForecast(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ValidateForecast checks a decoded forecast against the constraints of the
+// forecast and outcome tables.
+func ValidateForecast(f *dto.Forecast) error {
+	var errs []error
+
+	headline := strings.TrimSpace(f.Headline)
+	if headline == "" {
+		errs = append(errs, errors.New(`"headline" is required`))
+	} else if n := utf8.RuneCountInString(headline); n > MaxHeadlineLength {
+		errs = append(errs, fmt.Errorf(`"headline" must be at most %d characters, got %d`, MaxHeadlineLength, n))
+	}
+
+	if strings.TrimSpace(f.Summary) == "" {
+		errs = append(errs, errors.New(`"summary" is required`))
+	}
+
+	if len(f.Outcomes) == 0 {
+		errs = append(errs, errors.New(`"outcomes" must contain at least one outcome`))
+	}
+
+	for i, o := range f.Outcomes {
+		if strings.TrimSpace(o.Content) == "" {
+			errs = append(errs, fmt.Errorf(`"outcomes[%d].content" is required`, i))
+		}
+		if o.ConfidenceLevel < MinConfidence || o.ConfidenceLevel > MaxConfidence {
+			errs = append(errs, fmt.Errorf(`"outcomes[%d].confidenceLevel" must be between %d and %d, got %d`, i, MinConfidence, MaxConfidence, o.ConfidenceLevel))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateSources checks that every outcome only cites articles among the
+// articleCount related articles in the prompt.
+func ValidateSources(f *dto.Forecast, articleCount int) error {
+	var errs []error
+
+	for i, o := range f.Outcomes {
+		for _, idx := range o.SourceIndexes {
+			if idx < 0 || idx >= articleCount {
+				errs = append(errs, fmt.Errorf(`"outcomes[%d].sources" must be indexes between 0 and %d, got %d`, i, articleCount-1, idx))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
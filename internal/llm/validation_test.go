@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseForecast(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{"plain", `{"headline": "Fed holds", "summary": "Rates stay.", "outcomes": [{"content": "Cut", "confidenceLevel": 60}]}`, ""},
+		{"prose around", "Here you go:\n{\"headline\": \"Fed holds\", \"summary\": \"Rates stay.\", \"outcomes\": [{\"content\": \"Cut\", \"confidenceLevel\": 60}]}\nThanks", ""},
+		{"not JSON", "Fed holds", "not valid JSON"},
+		{"empty headline", `{"headline": " ", "summary": "Rates stay.", "outcomes": [{"content": "Cut", "confidenceLevel": 60}]}`, `"headline" is required`},
+		{"long headline", `{"headline": "` + strings.Repeat("x", MaxHeadlineLength+1) + `", "summary": "Rates stay.", "outcomes": [{"content": "Cut", "confidenceLevel": 60}]}`, "at most 255 characters"},
+		{"confidence out of range", `{"headline": "Fed holds", "summary": "Rates stay.", "outcomes": [{"content": "Cut", "confidenceLevel": 120}]}`, `"outcomes[0].confidenceLevel" must be between 0 and 100`},
+		{"every error", `{"outcomes": [{"content": ""}]}`, `"summary" is required`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecast, err := ParseForecast(tt.output)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseForecast() error: %v", err)
+				}
+				if forecast.Headline != "Fed holds" {
+					t.Errorf("Headline = %q", forecast.Headline)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseForecast() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
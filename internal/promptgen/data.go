@@ -0,0 +1,37 @@
+package promptgen
+
+import (
+	"github.com/qoentz/evedict/internal/eventfeed"
+)
+
+// The data each template is executed with. ExtractKeywords receives a plain
+// eventfeed.Article.
+
+type NewsForecastData struct {
+	MainArticle     eventfeed.Article
+	RelatedArticles []eventfeed.Article
+}
+
+type MarketForecastData struct {
+	MainArticle     eventfeed.Article
+	RelatedArticles []eventfeed.Article
+	Event           eventfeed.Event
+}
+
+type SelectArticlesData struct {
+	Articles []eventfeed.Article
+}
+
+type SelectMarketsData struct {
+	Events []eventfeed.Event
+}
+
+type SelectArticleForEventData struct {
+	Event    eventfeed.Event
+	Articles []eventfeed.Article
+}
+
+type TranslateArticleData struct {
+	Language string
+	Article  eventfeed.Article
+}
@@ -0,0 +1,95 @@
+package promptgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FuncMap holds the helpers available in every prompt template.
+var FuncMap = template.FuncMap{
+	"truncate": truncate,
+	"json":     toJSON,
+	"date":     formatDate,
+	"join":     join,
+	"inc":      func(i int) int { return i + 1 },
+	"lower":    func(s string) string { return inFence(s, strings.ToLower) },
+	"upper":    func(s string) string { return inFence(s, strings.ToUpper) },
+	"trim":     strings.TrimSpace,
+	"sanitize": Sanitize,
+	"fence":    Fence,
+}
+
+// truncate shortens s to at most n runes, ending in "..." when cut. Fenced
+// text is shortened inside the fence, so the closing tag is kept.
+func truncate(n int, s string) string {
+	return inFence(s, func(s string) string { return truncateText(n, s) })
+}
+
+func truncateText(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return strings.TrimSpace(string(runes[:n-3])) + "..."
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("error encoding json: %v", err)
+	}
+	return string(b), nil
+}
+
+// formatDate formats a time.Time, or a timestamp in RFC 3339 as the feeds
+// deliver it, with layout. Timestamps it can't parse are returned as they are.
+func formatDate(layout string, v interface{}) (string, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	case string:
+		if t == "" {
+			return "", nil
+		}
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return t, nil
+		}
+		return parsed.Format(layout), nil
+	default:
+		return "", fmt.Errorf("date: unsupported value of type %T", v)
+	}
+}
+
+// join joins a list of strings, or of anything with a String method.
+func join(sep string, items interface{}) (string, error) {
+	switch list := items.(type) {
+	case []string:
+		return strings.Join(list, sep), nil
+	case []fmt.Stringer:
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = item.String()
+		}
+		return strings.Join(parts, sep), nil
+	case []interface{}:
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep), nil
+	default:
+		return "", fmt.Errorf("join: unsupported value of type %T", items)
+	}
+}
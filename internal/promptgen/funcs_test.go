@@ -0,0 +1,57 @@
+package promptgen
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestFuncMap(t *testing.T) {
+	published := time.Date(2025, time.June, 2, 15, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		data     interface{}
+		want     string
+	}{
+		{"truncate short", `{{truncate 20 .}}`, "Fed holds", "Fed holds"},
+		{"truncate long", `{{truncate 10 .}}`, "Fed holds rates steady", "Fed hol..."},
+		{"truncate tiny", `{{truncate 2 .}}`, "Fed holds", "Fe"},
+		{"truncate runes", `{{truncate 5 .}}`, "Zentralbank senkt", "Ze..."},
+		{"json", `{{json .}}`, map[string]int{"a": 1}, `{"a":1}`},
+		{"date of time", `{{date "Jan 2" .}}`, published, "Jun 2"},
+		{"date of pointer", `{{date "Jan 2" .}}`, &published, "Jun 2"},
+		{"date of RFC 3339", `{{date "2006-01-02" .}}`, "2025-06-02T15:04:00Z", "2025-06-02"},
+		{"date unparsed", `{{date "2006-01-02" .}}`, "yesterday", "yesterday"},
+		{"date empty", `{{date "2006-01-02" .}}`, "", ""},
+		{"join strings", `{{join ", " .}}`, []string{"fed", "rates"}, "fed, rates"},
+		{"join anything", `{{join "/" .}}`, []interface{}{1, "a"}, "1/a"},
+		{"inc", `{{inc .}}`, 0, "1"},
+		{"case and trim", `{{lower .}} {{upper .}} {{trim "  x "}}`, "Fed", "fed FED x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("").Funcs(FuncMap).Parse(tt.template))
+
+			var b strings.Builder
+			if err := tmpl.Execute(&b, tt.data); err != nil {
+				t.Fatalf("Execute() error: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("%s = %q, want %q", tt.template, b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncMapErrors(t *testing.T) {
+	for _, text := range []string{`{{date "Jan 2" 42}}`, `{{join ", " 42}}`} {
+		tmpl := template.Must(template.New("").Funcs(FuncMap).Parse(text))
+		if err := tmpl.Execute(&strings.Builder{}, nil); err == nil {
+			t.Errorf("%s returned no error", text)
+		}
+	}
+}
@@ -1,13 +1,21 @@
 package promptgen
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"gopkg.in/yaml.v2"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"text/template"
 )
 
+// TemplateType names a prompt. The constants are the prompts the pipeline
+// uses; the prompt files may define any others.
 type TemplateType string
 
 const (
@@ -17,18 +25,200 @@ const (
 	SelectMarkets          TemplateType = "select_markets"
 	SelectArticleForEvent  TemplateType = "select_article_for_event"
 	ExtractKeywords        TemplateType = "extract_keywords"
+	TranslateArticle       TemplateType = "translate_article"
 )
 
+// DefaultPath is where the prompts are loaded from unless configured
+// otherwise.
+const DefaultPath = "internal/promptgen/prompts.yaml"
+
+// partialsKey is the prompt file entry holding the shared partials.
+const partialsKey = "partials"
+
+// defaultTranslatePrompt is used when the prompt files have no
+// translate_article template.
+const defaultTranslatePrompt = `Translate the following news article from {{.Language}} into English. Keep names, numbers and quotes accurate and do not add or leave out anything.
+
+Title: {{.Article.Title}}
+Description: {{.Article.Description}}
+Content: {{.Article.Content}}
+
+Return only a JSON object with the keys "title", "description" and "content", each holding the English translation of that field. Leave a field empty if it is empty above.`
+
+// builtinPrompts fill in for prompts the files leave out.
+var builtinPrompts = map[TemplateType]rawPrompt{
+	TranslateArticle: {Version: "builtin", Template: defaultTranslatePrompt},
+}
+
+// CitationsPartial asks for the rationale and sources of every outcome.
+// Forecast templates that don't call it get it appended, so it is part of
+// their hash. The prompt files may redefine it.
+const CitationsPartial = "citations"
+
+const defaultCitationsPartial = `For every outcome, also include:
+- "rationale": one or two sentences on why the outcome got its confidence level.
+- "sources": the 0-based indexes of the related articles that support the outcome, e.g. [0, 2].`
+
+// builtinPartials fill in for partials the files leave out.
+var builtinPartials = map[string]string{
+	CitationsPartial: defaultCitationsPartial,
+}
+
+// citingTemplates are the prompts whose outcomes cite the related articles.
+var citingTemplates = map[TemplateType]bool{
+	GenerateNewsForecast:   true,
+	GenerateMarketForecast: true,
+}
+
+var citationsCall = regexp.MustCompile(`\{\{-?\s*template\s+"` + CitationsPartial + `"`)
+
+var TemplateTypes = []TemplateType{
+	GenerateNewsForecast,
+	GenerateMarketForecast,
+	SelectArticles,
+	SelectMarkets,
+	SelectArticleForEvent,
+	ExtractKeywords,
+	TranslateArticle,
+}
+
+func ParseTemplateType(s string) (TemplateType, error) {
+	for _, t := range TemplateTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid template type: %s", s)
+}
+
+// PromptTemplate is shared by every service rendering prompts. Reload swaps
+// in a new set of templates without the services noticing.
 type PromptTemplate struct {
+	set atomic.Pointer[templateSet]
+}
+
+// templateSet is never modified after parsing, so it can be read without
+// locking while Reload replaces it.
+type templateSet struct {
+	// templates and versions are keyed by the full name, including the
+	// variant
 	templates map[TemplateType]*template.Template
+	versions  map[TemplateType]PromptVersion
+	variants  map[TemplateType][]variant
+}
+
+// PromptVersion identifies the prompt text a template was parsed from. Version
+// is whatever the prompt file declares; Hash changes with every edit of the
+// prompt or the partials.
+type PromptVersion struct {
+	Version string
+	Hash    string
+	Variant string
+	Weight  int
+}
+
+// rawPrompt is an entry of the prompt file, given either as the template text
+// or with a version and a weight:
+//
+//	generate_news_forecast:
+//	  version: "3"
+//	  template: |
+//	    ...
+//	generate_news_forecast@concise:
+//	  weight: 2
+//	  template: |
+//	    ...
+type rawPrompt struct {
+	Version  string `yaml:"version"`
+	Weight   *int   `yaml:"weight"`
+	Template string `yaml:"template"`
+}
+
+func (p *rawPrompt) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err == nil {
+		p.Template = text
+		return nil
+	}
+
+	type plain rawPrompt
+	return unmarshal((*plain)(p))
+}
+
+// rawPrompts collects the prompts and partials of every prompt file.
+type rawPrompts struct {
+	prompts  map[TemplateType]rawPrompt
+	partials map[string]string
 }
 
 func (p *PromptTemplate) CreatePrompt(templateType TemplateType, data interface{}) (string, error) {
-	return p.executeTemplate(templateType, data)
+	prompt, _, err := p.Render(templateType, data)
+	return prompt, err
+}
+
+// Render executes a variant of templateType picked by weight, or exactly the
+// variant templateType names, and returns the version it used. Text from the
+// feeds in data is sanitized and fenced first, see sanitizeData.
+func (p *PromptTemplate) Render(templateType TemplateType, data interface{}) (string, PromptVersion, error) {
+	set := p.set.Load()
+	return set.render(set.pick(templateType), data)
 }
 
-func (p *PromptTemplate) executeTemplate(templateType TemplateType, data interface{}) (string, error) {
-	tmpl, exists := p.templates[templateType]
+// RenderVariant executes exactly the template name, such as a name Pick
+// returned. Unlike Render, it doesn't draw again when name is the plain name
+// of the default variant.
+func (p *PromptTemplate) RenderVariant(name TemplateType, data interface{}) (string, PromptVersion, error) {
+	return p.set.Load().render(name, data)
+}
+
+// Pick chooses a variant of templateType by weight and returns its full name,
+// so that several prompts can be rendered with the same variant.
+func (p *PromptTemplate) Pick(templateType TemplateType) TemplateType {
+	return p.set.Load().pick(templateType)
+}
+
+// Version returns the version of the template for templateType.
+func (p *PromptTemplate) Version(templateType TemplateType) PromptVersion {
+	return p.set.Load().versions[templateType]
+}
+
+// Has reports whether a template named templateType, or a variant of it, was
+// loaded.
+func (p *PromptTemplate) Has(templateType TemplateType) bool {
+	set := p.set.Load()
+	if _, exists := set.templates[templateType]; exists {
+		return true
+	}
+	return len(set.variants[templateType]) > 0
+}
+
+// Names lists the loaded templates and variants in alphabetical order.
+func (p *PromptTemplate) Names() []TemplateType {
+	templates := p.set.Load().templates
+
+	names := make([]TemplateType, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func (s *templateSet) render(name TemplateType, data interface{}) (string, PromptVersion, error) {
+	prompt, err := s.execute(name, sanitizeData(data))
+	if err != nil {
+		return "", PromptVersion{}, err
+	}
+
+	if strings.Contains(prompt, fenceOpen) {
+		prompt = untrustedNotice + "\n\n" + prompt
+	}
+
+	return prompt, s.versions[name], nil
+}
+
+func (s *templateSet) execute(templateType TemplateType, data interface{}) (string, error) {
+	tmpl, exists := s.templates[templateType]
 	if !exists {
 		return "", fmt.Errorf("invalid template type: %s", templateType)
 	}
@@ -41,40 +231,248 @@ func (p *PromptTemplate) executeTemplate(templateType TemplateType, data interfa
 	return result.String(), nil
 }
 
-func LoadPromptTemplate(filepath string) (*PromptTemplate, error) {
-	var rawPrompts struct {
-		GenerateNewsForecast   string `yaml:"generate_news_forecast"`
-		GenerateMarketForecast string `yaml:"generate_market_forecast"`
-		SelectArticles         string `yaml:"select_articles"`
-		SelectMarkets          string `yaml:"select_markets"`
-		SelectArticleForEvent  string `yaml:"select_article_for_event"`
-		ExtractKeywords        string `yaml:"extract_keywords"`
+// LoadPromptTemplate loads the prompts from a YAML file, or from every file in
+// a directory. A YAML file maps prompt names to templates and may hold shared
+// partials under "partials":
+//
+//	partials:
+//	  article: |
+//	    {{.Title}} ({{.Source.Name}}, {{date "Jan 2" .PublishedAt}})
+//	extract_keywords: |
+//	  ... {{template "article" .}} ...
+//
+// In a directory, "name.tmpl" files add the prompt "name" and "_name.tmpl"
+// files the partial "name". Templates may also {{define}} partials inline.
+//
+// A prompt named "name@variant" is a variant of "name". Render picks one of
+// the variants by weight, so they can be compared on live traffic.
+func LoadPromptTemplate(path string) (*PromptTemplate, error) {
+	raw := &rawPrompts{
+		prompts:  map[TemplateType]rawPrompt{},
+		partials: map[string]string{},
 	}
 
-	file, err := os.ReadFile(filepath)
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := yaml.Unmarshal(file, &rawPrompts); err != nil {
+	if info.IsDir() {
+		err = raw.loadDir(path)
+	} else {
+		err = raw.loadFile(path)
+	}
+	if err != nil {
 		return nil, err
 	}
 
+	for name, prompt := range builtinPrompts {
+		if _, exists := raw.prompts[name]; !exists {
+			raw.prompts[name] = prompt
+		}
+	}
+	for name, text := range builtinPartials {
+		if _, exists := raw.partials[name]; !exists {
+			raw.partials[name] = text
+		}
+	}
+
+	set, err := raw.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PromptTemplate{}
+	p.set.Store(set)
+	return p, nil
+}
+
+// Reload loads and validates the prompts at path and swaps them in. On error
+// the current templates stay in use.
+func (p *PromptTemplate) Reload(path string) error {
+	next, err := LoadPromptTemplate(path)
+	if err != nil {
+		return err
+	}
+
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	p.set.Store(next.set.Load())
+	return nil
+}
+
+func (r *rawPrompts) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		ext := filepath.Ext(entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ext)
+
+		switch ext {
+		case ".yaml", ".yml":
+			err = r.loadFile(path)
+		case ".tmpl":
+			var text []byte
+			text, err = os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(name, "_") {
+				err = r.addPartial(strings.TrimPrefix(name, "_"), string(text))
+			} else {
+				err = r.addPrompt(TemplateType(name), rawPrompt{Template: string(text)})
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *rawPrompts) loadFile(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// The partials entry decodes as an empty prompt, so it is read separately
+	var prompts map[string]rawPrompt
+	if err := yaml.Unmarshal(file, &prompts); err != nil {
+		return fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	var partials struct {
+		Partials map[string]string `yaml:"partials"`
+	}
+	if err := yaml.Unmarshal(file, &partials); err != nil {
+		return fmt.Errorf("error parsing partials in %s: %v", path, err)
+	}
+
+	for name, text := range partials.Partials {
+		if err := r.addPartial(name, text); err != nil {
+			return err
+		}
+	}
+
+	for name, prompt := range prompts {
+		if name == partialsKey {
+			continue
+		}
+		if err := r.addPrompt(TemplateType(name), prompt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *rawPrompts) addPrompt(name TemplateType, prompt rawPrompt) error {
+	if _, exists := r.prompts[name]; exists {
+		return fmt.Errorf("prompt %q is defined more than once", name)
+	}
+	r.prompts[name] = prompt
+	return nil
+}
+
+func (r *rawPrompts) addPartial(name, text string) error {
+	if _, exists := r.partials[name]; exists {
+		return fmt.Errorf("partial %q is defined more than once", name)
+	}
+	r.partials[name] = text
+	return nil
+}
+
+// parse builds every prompt on top of a common set of partials, so a
+// {{template}} call works in any prompt.
+func (r *rawPrompts) parse() (*templateSet, error) {
+	base := template.New("").Funcs(FuncMap)
+
+	partialNames := make([]string, 0, len(r.partials))
+	for name := range r.partials {
+		partialNames = append(partialNames, name)
+	}
+	sort.Strings(partialNames)
+
+	partialsHash := sha256.New()
+	for _, name := range partialNames {
+		if _, err := base.New(name).Parse(r.partials[name]); err != nil {
+			return nil, fmt.Errorf("error parsing partial %q: %v", name, err)
+		}
+		partialsHash.Write([]byte(name + "\x00" + r.partials[name] + "\x00"))
+	}
+	partialsSum := hex.EncodeToString(partialsHash.Sum(nil))
+
 	templates := map[TemplateType]*template.Template{}
-	for key, value := range map[TemplateType]string{
-		GenerateNewsForecast:   rawPrompts.GenerateNewsForecast,
-		GenerateMarketForecast: rawPrompts.GenerateMarketForecast,
-		SelectArticles:         rawPrompts.SelectArticles,
-		SelectMarkets:          rawPrompts.SelectMarkets,
-		SelectArticleForEvent:  rawPrompts.SelectArticleForEvent,
-		ExtractKeywords:        rawPrompts.ExtractKeywords,
-	} {
-		tmpl, err := template.New(string(key)).Parse(value)
+	versions := map[TemplateType]PromptVersion{}
+	variants := map[TemplateType][]variant{}
+	for name, prompt := range r.prompts {
+		templateType, variantName := SplitVariant(name)
+		if templateType == "" {
+			return nil, fmt.Errorf("invalid prompt name %q", name)
+		}
+
+		weight := 1
+		if prompt.Weight != nil {
+			weight = *prompt.Weight
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("prompt %q has a negative weight", name)
+		}
+
+		text := prompt.Template
+		if citingTemplates[templateType] && !citationsCall.MatchString(text) {
+			text = strings.TrimRight(text, "\n") + "\n\n{{template \"" + CitationsPartial + "\" .}}\n"
+		}
+
+		set, err := base.Clone()
 		if err != nil {
-			return nil, fmt.Errorf("error parsing template %q: %v", key, err)
+			return nil, err
+		}
+
+		tmpl, err := set.New(string(name)).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template %q: %v", name, err)
+		}
+
+		templates[name] = tmpl
+		versions[name] = PromptVersion{
+			Version: prompt.Version,
+			Hash:    hashPrompt(text, partialsSum),
+			Variant: variantName,
+			Weight:  weight,
+		}
+		variants[templateType] = append(variants[templateType], variant{name: name, weight: weight})
+	}
+
+	for templateType, list := range variants {
+		total := 0
+		for _, v := range list {
+			total += v.weight
 		}
-		templates[key] = tmpl
+		if total == 0 {
+			return nil, fmt.Errorf("prompt %q has no variant with a weight above 0", templateType)
+		}
+
+		// Map iteration order must not decide which variant a draw lands on
+		sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
 	}
 
-	return &PromptTemplate{templates: templates}, nil
+	return &templateSet{templates: templates, versions: versions, variants: variants}, nil
+}
+
+func hashPrompt(text, partialsSum string) string {
+	sum := sha256.Sum256([]byte(text + "\x00" + partialsSum))
+	return hex.EncodeToString(sum[:])[:12]
 }
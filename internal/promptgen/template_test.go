@@ -0,0 +1,250 @@
+package promptgen
+
+import (
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePrompts writes prompts to a temporary prompt file.
+func writePrompts(t *testing.T, prompts string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	if err := os.WriteFile(path, []byte(prompts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadTestPrompts loads prompts from YAML written to a temporary file.
+func loadTestPrompts(t *testing.T, prompts string) *PromptTemplate {
+	t.Helper()
+
+	p, err := LoadPromptTemplate(writePrompts(t, prompts))
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+	return p
+}
+
+func TestLoadPromptFile(t *testing.T) {
+	p := loadTestPrompts(t, `
+partials:
+  article: "{{.Title}} ({{upper .Source}})"
+extract_keywords: "Keywords for {{template \"article\" .}}"
+summarize:
+  version: "2"
+  template: "Summarize {{truncate 10 .Title}}"
+`)
+
+	data := struct{ Title, Source string }{"Fed holds rates steady", "reuters"}
+
+	prompt, err := p.CreatePrompt(ExtractKeywords, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt != "Keywords for Fed holds rates steady (REUTERS)" {
+		t.Errorf("extract_keywords = %q", prompt)
+	}
+
+	// Prompts are not limited to the ones the pipeline uses
+	prompt, err = p.CreatePrompt("summarize", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt != "Summarize Fed hol..." {
+		t.Errorf("summarize = %q", prompt)
+	}
+	if v := p.Version("summarize"); v.Version != "2" || v.Hash == "" {
+		t.Errorf("Version(summarize) = %+v", v)
+	}
+
+	// The built-in translation prompt fills in for the missing one
+	want := []TemplateType{ExtractKeywords, "summarize", TranslateArticle}
+	if got := p.Names(); strings.Join(toStrings(got), ",") != strings.Join(toStrings(want), ",") {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if p.Has(GenerateNewsForecast) {
+		t.Error("Has(generate_news_forecast) for a prompt that isn't defined")
+	}
+	if _, err := p.CreatePrompt(GenerateNewsForecast, data); err == nil {
+		t.Error("CreatePrompt() of an undefined prompt returned no error")
+	}
+}
+
+func TestLoadPromptDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_article.tmpl":         "{{.Title}}",
+		"extract_keywords.tmpl": "Keywords for {{template \"article\" .}}{{template \"footer\"}}",
+		"more.yaml":             "partials:\n  footer: \" - thanks\"\nsummarize: \"Summarize {{template \\\"article\\\" .}}\"\n",
+		"README.md":             "ignored",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := LoadPromptTemplate(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+
+	data := struct{ Title string }{"Fed holds"}
+	for name, want := range map[TemplateType]string{
+		ExtractKeywords: "Keywords for Fed holds - thanks",
+		"summarize":     "Summarize Fed holds",
+	} {
+		prompt, err := p.CreatePrompt(name, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompt != want {
+			t.Errorf("%s = %q, want %q", name, prompt, want)
+		}
+	}
+}
+
+func TestLoadPromptErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "prompt defined twice",
+			files:   map[string]string{"a.yaml": "summarize: a", "summarize.tmpl": "b"},
+			wantErr: `prompt "summarize" is defined more than once`,
+		},
+		{
+			name:    "partial defined twice",
+			files:   map[string]string{"a.yaml": "partials:\n  article: a", "_article.tmpl": "b"},
+			wantErr: `partial "article" is defined more than once`,
+		},
+		{
+			name:    "bad template",
+			files:   map[string]string{"summarize.tmpl": "{{.Title"},
+			wantErr: `error parsing template "summarize"`,
+		},
+		{
+			name:    "unknown function",
+			files:   map[string]string{"summarize.tmpl": "{{shout .Title}}"},
+			wantErr: `function "shout" not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, text := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := LoadPromptTemplate(dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPromptTemplate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPartialsChangeHash(t *testing.T) {
+	a := loadTestPrompts(t, "partials:\n  footer: a\nsummarize: \"{{template \\\"footer\\\"}}\"\n")
+	b := loadTestPrompts(t, "partials:\n  footer: b\nsummarize: \"{{template \\\"footer\\\"}}\"\n")
+
+	if a.Version("summarize").Hash == b.Version("summarize").Hash {
+		t.Error("editing a partial kept the prompt hash")
+	}
+}
+
+func TestCitationsPartial(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompts string
+		want    string
+	}{
+		{
+			name: "appended to forecast templates",
+			prompts: `
+generate_news_forecast: |
+  Forecast {{.MainArticle.Title}}.
+`,
+			want: "Forecast Fed holds rates.\n\n" + defaultCitationsPartial + "\n",
+		},
+		{
+			name: "placed by the template",
+			prompts: `
+generate_news_forecast: |
+  Cite like this:
+  {{- template "citations" .}}
+  Then forecast.
+`,
+			want: "Cite like this:" + defaultCitationsPartial + "\nThen forecast.\n",
+		},
+		{
+			name: "redefined by the prompt file",
+			prompts: `
+partials:
+  citations: Cite the articles by index.
+generate_news_forecast: Forecast.
+`,
+			want: "Forecast.\n\nCite the articles by index.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := loadTestPrompts(t, tt.prompts)
+
+			prompt, err := p.CreatePrompt(GenerateNewsForecast, NewsForecastData{
+				MainArticle: eventfeed.Article{Title: "Fed holds rates"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prompt != tt.want {
+				t.Errorf("prompt = %q, want %q", prompt, tt.want)
+			}
+		})
+	}
+}
+
+func TestCitationsOnlyInForecasts(t *testing.T) {
+	p := loadTestPrompts(t, `
+extract_keywords: Keywords for {{.Title}}.
+`)
+
+	prompt, err := p.CreatePrompt(ExtractKeywords, eventfeed.Article{Title: "Fed holds rates"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt != "Keywords for Fed holds rates." {
+		t.Errorf("prompt = %q", prompt)
+	}
+}
+
+func TestCitationsChangeHash(t *testing.T) {
+	builtin := loadTestPrompts(t, `generate_news_forecast: Forecast.`)
+	redefined := loadTestPrompts(t, `
+partials:
+  citations: Cite the articles by index.
+generate_news_forecast: Forecast.
+`)
+
+	if builtin.Version(GenerateNewsForecast).Hash == redefined.Version(GenerateNewsForecast).Hash {
+		t.Errorf("redefining the citations partial kept the hash %s", builtin.Version(GenerateNewsForecast).Hash)
+	}
+}
+
+func toStrings(names []TemplateType) []string {
+	s := make([]string, len(names))
+	for i, name := range names {
+		s[i] = string(name)
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package promptgen
+
+import (
+	"errors"
+	"fmt"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"strings"
+)
+
+// SampleData returns representative data for templateType, shaped like what
+// the pipeline passes at generation time. It returns false for templates the
+// pipeline doesn't use.
+func SampleData(templateType TemplateType) (interface{}, bool) {
+	articles := sampleArticles()
+	event := sampleEvent()
+
+	switch templateType {
+	case GenerateNewsForecast:
+		return NewsForecastData{MainArticle: articles[0], RelatedArticles: articles}, true
+	case GenerateMarketForecast:
+		return MarketForecastData{MainArticle: articles[0], RelatedArticles: articles, Event: event}, true
+	case SelectArticles:
+		return SelectArticlesData{Articles: articles}, true
+	case SelectMarkets:
+		return SelectMarketsData{Events: []eventfeed.Event{event, event}}, true
+	case SelectArticleForEvent:
+		return SelectArticleForEventData{Event: event, Articles: articles}, true
+	case ExtractKeywords:
+		return articles[0], true
+	case TranslateArticle:
+		return TranslateArticleData{Language: "German", Article: articles[0]}, true
+	default:
+		return nil, false
+	}
+}
+
+// Validate executes every variant of the templates the pipeline uses against
+// their sample data, so a missing template or a field that doesn't exist fails
+// at startup rather than at generation time.
+func (p *PromptTemplate) Validate() error {
+	var errs []error
+
+	for _, templateType := range TemplateTypes {
+		if !p.Has(templateType) {
+			errs = append(errs, fmt.Errorf("missing template %q", templateType))
+		}
+	}
+
+	for _, name := range p.Names() {
+		templateType, _ := SplitVariant(name)
+		data, ok := SampleData(templateType)
+		if !ok {
+			continue
+		}
+
+		prompt, err := p.CreatePrompt(name, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if strings.TrimSpace(prompt) == "" {
+			errs = append(errs, fmt.Errorf("template %q renders an empty prompt", name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sampleArticles() []eventfeed.Article {
+	sourceID := "reuters"
+
+	return []eventfeed.Article{
+		{
+			Feed:        "newsapi",
+			Source:      eventfeed.Publisher{ID: &sourceID, Name: "Reuters"},
+			Author:      "Jane Doe",
+			Title:       "Central bank holds rates steady as inflation cools",
+			Description: "Policymakers kept the benchmark rate unchanged and signaled cuts later this year.",
+			URL:         "https://example.com/markets/rates",
+			URLToImage:  "https://example.com/images/rates.jpg",
+			PublishedAt: "2025-07-01T14:30:00Z",
+			Content:     "The central bank left its key rate unchanged on Tuesday... [+2048 chars]",
+			Language:    "en",
+		},
+		{
+			Feed:        "newsapi",
+			Source:      eventfeed.Publisher{Name: "Associated Press"},
+			Author:      "John Roe",
+			Title:       "Stocks rally after rate decision",
+			Description: "Major indexes closed higher following the announcement.",
+			URL:         "https://example.com/markets/stocks",
+			URLToImage:  "https://example.com/images/stocks.jpg",
+			PublishedAt: "2025-07-01T20:05:00Z",
+			Content:     "Wall Street ended the session higher... [+1536 chars]",
+		},
+	}
+}
+
+func sampleEvent() eventfeed.Event {
+	return eventfeed.Event{
+		Feed:        "polymarket",
+		ID:          "12345",
+		Title:       "Fed rate cut in September?",
+		Description: "This market resolves to Yes if the Fed announces a rate cut at its September meeting.",
+		StartDate:   "2025-06-15T00:00:00Z",
+		Image:       "https://example.com/images/fed.png",
+		Volume:      1250000,
+		Tags:        []eventfeed.Tag{{ID: "1", Label: "Economy"}, {ID: "2", Label: "Fed"}},
+		Markets: []eventfeed.Market{{
+			ID:            "67890",
+			Question:      "Will the Fed cut rates in September?",
+			Description:   "Resolves Yes on a cut of any size.",
+			Outcomes:      `["Yes","No"]`,
+			OutcomePrices: `["0.62","0.38"]`,
+			Volume:        "1250000",
+			VolumeNum:     1250000,
+			Active:        true,
+		}},
+	}
+}
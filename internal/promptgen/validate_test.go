@@ -0,0 +1,56 @@
+package promptgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// validPrompts uses a field of the data of every template the pipeline uses.
+const validPrompts = `
+generate_news_forecast: "{{.MainArticle.Title}} {{len .RelatedArticles}}"
+generate_market_forecast: "{{.MainArticle.Title}} {{.Event.Title}}"
+select_articles: "{{range .Articles}}{{.Title}}{{end}}"
+select_markets: "{{range .Events}}{{.Title}}{{end}}"
+select_article_for_event: "{{.Event.Title}} {{len .Articles}}"
+extract_keywords: "{{.Title}} {{.Source.Name}}"
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompts string
+		wantErr string
+	}{
+		{"valid", validPrompts, ""},
+		{"missing template", strings.Replace(validPrompts, "select_markets:", "summarize:", 1), `missing template "select_markets"`},
+		{"unknown field", strings.Replace(validPrompts, "{{.Title}} {{.Source.Name}}", "{{.Headline}}", 1), "can't evaluate field Headline"},
+		{"empty prompt", strings.Replace(validPrompts, "{{range .Articles}}{{.Title}}{{end}}", "{{/* nothing */}}", 1), `template "select_articles" renders an empty prompt`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loadTestPrompts(t, tt.prompts).Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSampleData(t *testing.T) {
+	for _, templateType := range TemplateTypes {
+		if data, ok := SampleData(templateType); !ok || data == nil {
+			t.Errorf("no sample data for %s", templateType)
+		}
+	}
+
+	if _, ok := SampleData("summarize"); ok {
+		t.Error("sample data for a template the pipeline doesn't use")
+	}
+}
@@ -0,0 +1,66 @@
+package promptgen
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// DefaultVariant is the variant of a prompt defined under its plain name.
+const DefaultVariant = "default"
+
+// variantSeparator separates the variant from the prompt name, as in
+// "generate_news_forecast@concise".
+const variantSeparator = "@"
+
+type variant struct {
+	name   TemplateType
+	weight int
+}
+
+// SplitVariant splits a prompt name into the template type and the variant.
+func SplitVariant(name TemplateType) (TemplateType, string) {
+	base, variantName, found := strings.Cut(string(name), variantSeparator)
+	if !found || variantName == "" {
+		return TemplateType(base), DefaultVariant
+	}
+	return TemplateType(base), variantName
+}
+
+// VariantName is the inverse of SplitVariant.
+func VariantName(templateType TemplateType, variantName string) TemplateType {
+	if variantName == "" || variantName == DefaultVariant {
+		return templateType
+	}
+	return templateType + variantSeparator + TemplateType(variantName)
+}
+
+// pick returns templateType itself when it names a variant or has none, and
+// otherwise draws one of its variants by weight.
+func (s *templateSet) pick(templateType TemplateType) TemplateType {
+	if strings.Contains(string(templateType), variantSeparator) {
+		return templateType
+	}
+
+	list := s.variants[templateType]
+	switch len(list) {
+	case 0:
+		return templateType
+	case 1:
+		return list[0].name
+	}
+
+	total := 0
+	for _, v := range list {
+		total += v.weight
+	}
+
+	n := rand.Intn(total)
+	for _, v := range list {
+		if n < v.weight {
+			return v.name
+		}
+		n -= v.weight
+	}
+
+	return list[len(list)-1].name
+}
@@ -0,0 +1,111 @@
+package promptgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitVariant(t *testing.T) {
+	tests := []struct {
+		name         TemplateType
+		templateType TemplateType
+		variant      string
+	}{
+		{"generate_news_forecast", "generate_news_forecast", DefaultVariant},
+		{"generate_news_forecast@concise", "generate_news_forecast", "concise"},
+		{"generate_news_forecast@", "generate_news_forecast", DefaultVariant},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			templateType, variant := SplitVariant(tt.name)
+			if templateType != tt.templateType || variant != tt.variant {
+				t.Errorf("SplitVariant(%q) = %q, %q, want %q, %q", tt.name, templateType, variant, tt.templateType, tt.variant)
+			}
+		})
+	}
+
+	if got := VariantName("x", DefaultVariant); got != "x" {
+		t.Errorf("VariantName(x, default) = %q, want x", got)
+	}
+	if got := VariantName("x", "concise"); got != "x@concise" {
+		t.Errorf("VariantName(x, concise) = %q, want x@concise", got)
+	}
+}
+
+const variantPrompts = `
+extract_keywords: default
+extract_keywords@short:
+  weight: 3
+  template: short
+extract_keywords@off:
+  weight: 0
+  template: off
+`
+
+func TestPickByWeight(t *testing.T) {
+	p := loadTestPrompts(t, variantPrompts)
+
+	const draws = 4000
+	counts := map[TemplateType]int{}
+	for i := 0; i < draws; i++ {
+		counts[p.Pick(ExtractKeywords)]++
+	}
+
+	if counts["extract_keywords@off"] != 0 {
+		t.Errorf("a variant of weight 0 was picked %d times", counts["extract_keywords@off"])
+	}
+	// short has 3 of the 4 weights
+	if share := float64(counts["extract_keywords@short"]) / draws; share < 0.7 || share > 0.8 {
+		t.Errorf("short was picked %.2f of the time, want about 0.75", share)
+	}
+	if counts["extract_keywords"]+counts["extract_keywords@short"] != draws {
+		t.Errorf("picked unknown variants: %v", counts)
+	}
+
+	if got := p.Pick("extract_keywords@off"); got != "extract_keywords@off" {
+		t.Errorf("Pick of a variant name = %q, want it unchanged", got)
+	}
+}
+
+func TestRenderVariant(t *testing.T) {
+	p := loadTestPrompts(t, variantPrompts)
+
+	for i := 0; i < 50; i++ {
+		prompt, version, err := p.RenderVariant(ExtractKeywords, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prompt != "default" || version.Variant != DefaultVariant {
+			t.Fatalf("RenderVariant() rendered %q of variant %q, want the default", prompt, version.Variant)
+		}
+	}
+
+	prompt, version, err := p.RenderVariant("extract_keywords@off", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompt != "off" || version.Variant != "off" || version.Weight != 0 {
+		t.Errorf("RenderVariant(off) = %q, %+v", prompt, version)
+	}
+}
+
+func TestVariantWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompts string
+		wantErr string
+	}{
+		{"negative weight", "x:\n  weight: -1\n  template: x\n", "negative weight"},
+		{"no weight above zero", "x:\n  weight: 0\n  template: x\nx@b:\n  weight: 0\n  template: b\n", "no variant with a weight above 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePrompts(t, tt.prompts)
+			if _, err := LoadPromptTemplate(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPromptTemplate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/qoentz/evedict/config"
+	"github.com/qoentz/evedict/internal/db/repository"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/llm/cache"
+	"github.com/qoentz/evedict/internal/llm/ensemble"
+	"github.com/qoentz/evedict/internal/llm/local"
+	"github.com/qoentz/evedict/internal/llm/openai"
+	"github.com/qoentz/evedict/internal/llm/replicate"
+	"github.com/qoentz/evedict/internal/llm/stub"
+	"github.com/qoentz/evedict/internal/llm/usage"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"os"
+	"time"
+)
+
+const localTimeout = 5 * time.Minute
+
+const defaultCacheDir = ".cache/llm"
+
+// newAIService also returns the forecaster for ensemble mode or sampling, or
+// nil when both are off. Their outcomes are matched with embedder when set,
+// and every forecast attempt is told to attempts.
+func newAIService(c *config.SystemConfig, db *sqlx.DB, embedder llm.Embedder, attempts llm.AttemptRecorder) (llm.Service, ensemble.Forecaster, error) {
+	cfg := c.EnvConfig.LLMConfig
+
+	pricing, err := usage.ParsePricing(cfg.Pricing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing LLM_PRICING: %v", err)
+	}
+
+	providers := &llmProviders{
+		c:         c,
+		db:        db,
+		recorder:  repository.NewLLMCallRepository(db),
+		pricing:   pricing,
+		providers: map[string]llm.Provider{},
+	}
+
+	router, err := newRouter(c, providers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	forecaster, err := newForecaster(c, providers, router, attempts, ensemble.NewMatcher(embedder))
+	if err != nil {
+		return nil, nil, fmt.Errorf("ensemble: %v", err)
+	}
+
+	return llm.NewPromptService(router, c.PromptTemplate, cfg.ForecastAttempts, attempts), forecaster, nil
+}
+
+// llmProviders builds each provider named by the default provider, the routes
+// or the ensemble once, wrapped in usage recording and caching.
+type llmProviders struct {
+	c         *config.SystemConfig
+	db        *sqlx.DB
+	recorder  usage.Recorder
+	pricing   usage.Pricing
+	providers map[string]llm.Provider
+}
+
+func (p *llmProviders) get(name string) (llm.Provider, error) {
+	cfg := p.c.EnvConfig.LLMConfig
+
+	if name == "" {
+		name = cfg.Provider
+	}
+	if name == "" {
+		name = "replicate"
+	}
+
+	if provider, ok := p.providers[name]; ok {
+		return provider, nil
+	}
+
+	provider, err := newLLMProvider(p.c, name)
+	if err != nil {
+		return nil, err
+	}
+	provider = usage.NewRecordingProvider(provider, p.recorder, p.pricing)
+
+	// Cache hits never reach the recorder, so only real calls are accounted
+	provider, err = withCache(provider, cfg, p.db)
+	if err != nil {
+		return nil, err
+	}
+
+	p.providers[name] = provider
+	return provider, nil
+}
+
+func newRouter(c *config.SystemConfig, providers *llmProviders) (*llm.Router, error) {
+	defaultRoute, err := newRoute(c.LLMRoutes.Default, providers)
+	if err != nil {
+		return nil, fmt.Errorf("default route: %v", err)
+	}
+
+	routes := map[promptgen.TemplateType]llm.Route{}
+	for name, routeConfig := range c.LLMRoutes.Routes {
+		templateType := promptgen.TemplateType(name)
+		if !c.PromptTemplate.Has(templateType) {
+			return nil, fmt.Errorf("route %s: no prompt template with that name", name)
+		}
+
+		route, err := newRoute(inheritRoute(routeConfig, c.LLMRoutes.Default), providers)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %v", name, err)
+		}
+
+		routes[templateType] = route
+	}
+
+	return llm.NewRouter(defaultRoute, routes), nil
+}
+
+func newForecaster(c *config.SystemConfig, providers *llmProviders, router *llm.Router, attempts llm.AttemptRecorder, matcher ensemble.Matcher) (ensemble.Forecaster, error) {
+	ensembleConfig := c.LLMRoutes.Ensemble
+	if len(ensembleConfig.Members) == 0 {
+		if c.EnvConfig.LLMConfig.Samples <= 1 {
+			return nil, nil
+		}
+		return newSampledForecaster(c, router, attempts, matcher), nil
+	}
+
+	aggregate, err := ensemble.ParseAggregate(ensembleConfig.Aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]ensemble.Member, len(ensembleConfig.Members))
+	for i, memberConfig := range ensembleConfig.Members {
+		route, err := newRoute(inheritRoute(memberConfig.LLMRouteConfig, c.LLMRoutes.Default), providers)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: %v", i+1, err)
+		}
+
+		memberRouter := forecastRouter(router, func(llm.Route) llm.Route {
+			return route
+		})
+
+		members[i] = ensemble.Member{
+			Name:    memberConfig.Name,
+			Service: newSampledForecaster(c, memberRouter, attempts, matcher),
+		}
+		if members[i].Name == "" {
+			members[i].Name = llm.ModelFor(route.Provider, llm.Request{Model: route.Model})
+		}
+	}
+
+	if err := nameMembers(members, ensembleConfig.Members); err != nil {
+		return nil, err
+	}
+
+	return ensemble.NewEnsembleService(members, aggregate, matcher), nil
+}
+
+// nameMembers numbers the members that share a model, so each one's outcomes
+// can be told apart. Configured names must be unique.
+func nameMembers(members []ensemble.Member, configs []config.LLMMemberConfig) error {
+	count := map[string]int{}
+	for _, m := range members {
+		count[m.Name]++
+	}
+
+	seen := map[string]int{}
+	for i := range members {
+		name := members[i].Name
+		if count[name] == 1 {
+			continue
+		}
+		if configs[i].Name != "" {
+			if j, ok := seen[name]; ok {
+				return fmt.Errorf("member %d: name %s is taken by member %d", i+1, name, j+1)
+			}
+			seen[name] = i
+			continue
+		}
+		members[i].Name = fmt.Sprintf("%s #%d", name, i+1)
+	}
+
+	return nil
+}
+
+// newSampledForecaster wraps a forecaster over router in a Sampler when
+// LLM_SAMPLES asks for more than one sample.
+func newSampledForecaster(c *config.SystemConfig, router *llm.Router, attempts llm.AttemptRecorder, matcher ensemble.Matcher) ensemble.Forecaster {
+	cfg := c.EnvConfig.LLMConfig
+	if cfg.Samples <= 1 {
+		return llm.NewPromptService(router, c.PromptTemplate, cfg.ForecastAttempts, attempts)
+	}
+
+	temperature := ensemble.DefaultSampleTemperature
+	if cfg.SampleTemperature != nil {
+		temperature = *cfg.SampleTemperature
+	}
+
+	sampleRouter := forecastRouter(router, func(route llm.Route) llm.Route {
+		route.Temperature = &temperature
+		return route
+	})
+
+	return ensemble.NewSampler(llm.NewPromptService(sampleRouter, c.PromptTemplate, cfg.ForecastAttempts, attempts), cfg.Samples, matcher)
+}
+
+// forecastRouter copies router with the routes of the forecast templates
+// replaced by override.
+func forecastRouter(router *llm.Router, override func(llm.Route) llm.Route) *llm.Router {
+	routes := map[promptgen.TemplateType]llm.Route{}
+	for templateType, route := range router.Routes {
+		routes[templateType] = route
+	}
+
+	for _, templateType := range []promptgen.TemplateType{promptgen.GenerateNewsForecast, promptgen.GenerateMarketForecast} {
+		routes[templateType] = override(router.Route(templateType))
+	}
+
+	return llm.NewRouter(router.Default, routes)
+}
+
+// inheritRoute fills the fields a route leaves empty from the default route.
+// The default model only applies when the route keeps the default provider.
+func inheritRoute(rc config.LLMRouteConfig, defaultConfig config.LLMRouteConfig) config.LLMRouteConfig {
+	if rc.Provider == "" {
+		rc.Provider = defaultConfig.Provider
+		if rc.Model == "" {
+			rc.Model = defaultConfig.Model
+			if rc.ContextWindow == 0 {
+				rc.ContextWindow = defaultConfig.ContextWindow
+			}
+		}
+	}
+	if rc.Temperature == nil {
+		rc.Temperature = defaultConfig.Temperature
+	}
+	return rc
+}
+
+func newRoute(rc config.LLMRouteConfig, providers *llmProviders) (llm.Route, error) {
+	provider, err := providers.get(rc.Provider)
+	if err != nil {
+		return llm.Route{}, err
+	}
+
+	if llm.ModelFor(provider, llm.Request{Model: rc.Model}) == "" {
+		return llm.Route{}, fmt.Errorf("no model configured")
+	}
+
+	return llm.Route{
+		Provider:      provider,
+		Model:         rc.Model,
+		MaxTokens:     rc.MaxTokens,
+		Temperature:   rc.Temperature,
+		ContextWindow: rc.ContextWindow,
+	}, nil
+}
+
+// newEmbedder reuses the connection settings of the chat providers with the
+// embedding model. It returns nil when no embedding provider is configured.
+func newEmbedder(c *config.SystemConfig) (llm.Embedder, error) {
+	cfg := c.EnvConfig.LLMConfig
+
+	switch cfg.EmbeddingProvider {
+	case "":
+		return nil, nil
+	case "stub":
+		return stub.NewStubService(cfg.StubFixtureDir, cfg.StubSeed)
+	case "openai":
+		if cfg.OpenAIBaseURL == "" || cfg.EmbeddingModel == "" {
+			return nil, fmt.Errorf("openai embeddings require OPENAI_BASE_URL and LLM_EMBEDDING_MODEL")
+		}
+		return openai.NewOpenAIService(c.HTTPClient, cfg.OpenAIBaseURL, cfg.EmbeddingModel, cfg.OpenAIAPIKey), nil
+	case "local":
+		server, err := local.ParseServer(cfg.LocalServer)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.LocalURL == "" {
+			return nil, fmt.Errorf("local embeddings require LOCAL_LLM_URL")
+		}
+		if server == local.Ollama && cfg.EmbeddingModel == "" {
+			return nil, fmt.Errorf("ollama embeddings require LLM_EMBEDDING_MODEL")
+		}
+		return local.NewLocalService(c.HTTPClient, server, cfg.LocalURL, cfg.EmbeddingModel, nil, ""), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s", cfg.EmbeddingProvider)
+	}
+}
+
+func withCache(provider llm.Provider, cfg *config.LLMConfig, db *sqlx.DB) (llm.Provider, error) {
+	switch cfg.Cache {
+	case "":
+		return provider, nil
+	case "file":
+		dir := cfg.CacheDir
+		if dir == "" {
+			dir = defaultCacheDir
+		}
+		store, err := cache.NewFileStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		return cache.NewCachedProvider(provider, store, cfg.CacheTTL), nil
+	case "postgres":
+		return cache.NewCachedProvider(provider, repository.NewLLMCacheRepository(db), cfg.CacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM cache backend: %s", cfg.Cache)
+	}
+}
+
+func newLLMProvider(c *config.SystemConfig, name string) (llm.Provider, error) {
+	cfg := c.EnvConfig.LLMConfig
+
+	switch name {
+	case "replicate":
+		if cfg.ReplicateAPIKey == "" {
+			return nil, fmt.Errorf("replicate provider requires REPLICATE_KEY")
+		}
+		return replicate.NewReplicateService(c.HTTPClient, cfg.ReplicateModel, cfg.ReplicateAPIKey, cfg.ReplicateStream), nil
+	case "openai":
+		if cfg.OpenAIBaseURL == "" {
+			return nil, fmt.Errorf("openai provider requires OPENAI_BASE_URL")
+		}
+		return openai.NewOpenAIService(c.HTTPClient, cfg.OpenAIBaseURL, cfg.OpenAIModel, cfg.OpenAIAPIKey), nil
+	case "local":
+		server, err := local.ParseServer(cfg.LocalServer)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.LocalURL == "" {
+			return nil, fmt.Errorf("local provider requires LOCAL_LLM_URL")
+		}
+
+		var grammar string
+		if cfg.LocalGrammarFile != "" {
+			b, err := os.ReadFile(cfg.LocalGrammarFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading grammar file: %v", err)
+			}
+			grammar = string(b)
+		}
+
+		// Generation on a laptop is far slower than a hosted API, so don't
+		// inherit the short default timeout.
+		client := *c.HTTPClient
+		client.Timeout = localTimeout
+
+		return local.NewLocalService(&client, server, cfg.LocalURL, cfg.LocalModel, cfg.LocalTemperature, grammar), nil
+	case "stub":
+		return stub.NewStubService(cfg.StubFixtureDir, cfg.StubSeed)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", name)
+	}
+}
@@ -0,0 +1,309 @@
+package registry
+
+import (
+	"context"
+	"github.com/qoentz/evedict/config"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/llm/ensemble"
+	"github.com/qoentz/evedict/internal/llm/stub"
+	"github.com/qoentz/evedict/internal/llm/usage"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// namedProvider answers with its name.
+type namedProvider string
+
+func (p namedProvider) Complete(context.Context, llm.Request) (*llm.Response, error) {
+	return &llm.Response{Output: string(p)}, nil
+}
+
+func (p namedProvider) ModelName() string {
+	return string(p) + "-model"
+}
+
+func loadPrompts(t *testing.T) *promptgen.PromptTemplate {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	prompts := "generate_news_forecast: Forecast.\nselect_articles: Select.\nextract_keywords: Keywords.\n"
+	if err := os.WriteFile(path, []byte(prompts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := promptgen.LoadPromptTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+	return p
+}
+
+func TestInheritRoute(t *testing.T) {
+	zero := 0.0
+	warm := 0.7
+	defaultConfig := config.LLMRouteConfig{Provider: "replicate", Model: "meta/meta-llama-3-70b-instruct", MaxTokens: 512, Temperature: &warm}
+
+	tests := []struct {
+		name  string
+		route config.LLMRouteConfig
+		want  config.LLMRouteConfig
+	}{
+		{
+			name:  "empty",
+			route: config.LLMRouteConfig{},
+			want:  config.LLMRouteConfig{Provider: "replicate", Model: "meta/meta-llama-3-70b-instruct", Temperature: &warm},
+		},
+		{
+			name:  "model only",
+			route: config.LLMRouteConfig{Model: "meta/meta-llama-3-8b-instruct"},
+			want:  config.LLMRouteConfig{Provider: "replicate", Model: "meta/meta-llama-3-8b-instruct", Temperature: &warm},
+		},
+		{
+			name:  "provider only",
+			route: config.LLMRouteConfig{Provider: "openai"},
+			want:  config.LLMRouteConfig{Provider: "openai", Temperature: &warm},
+		},
+		{
+			name:  "zero temperature",
+			route: config.LLMRouteConfig{Provider: "openai", Model: "gpt-4o-mini", MaxTokens: 100, Temperature: &zero},
+			want:  config.LLMRouteConfig{Provider: "openai", Model: "gpt-4o-mini", MaxTokens: 100, Temperature: &zero},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inheritRoute(tt.route, defaultConfig)
+			if got.Provider != tt.want.Provider || got.Model != tt.want.Model || got.MaxTokens != tt.want.MaxTokens || got.Temperature != tt.want.Temperature {
+				t.Errorf("inheritRoute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouter(t *testing.T) {
+	warm := 0.7
+	c := &config.SystemConfig{
+		EnvConfig:      &config.EnvConfig{LLMConfig: &config.LLMConfig{Provider: "replicate"}},
+		PromptTemplate: loadPrompts(t),
+		LLMRoutes: &config.LLMRoutesConfig{
+			Default: config.LLMRouteConfig{Model: "meta/meta-llama-3-70b-instruct", Temperature: &warm},
+			Routes: map[string]config.LLMRouteConfig{
+				"select_articles":  {Provider: "openai", Model: "gpt-4o-mini"},
+				"extract_keywords": {Model: "meta/meta-llama-3-8b-instruct", MaxTokens: 20},
+			},
+			Ensemble: config.LLMEnsembleConfig{
+				Members: []config.LLMMemberConfig{
+					{LLMRouteConfig: config.LLMRouteConfig{Provider: "openai", Model: "gpt-4o"}},
+					{},
+				},
+			},
+		},
+	}
+
+	// The providers are built already, so get hands these out by name
+	providers := &llmProviders{
+		c: c,
+		providers: map[string]llm.Provider{
+			"replicate": namedProvider("replicate"),
+			"openai":    namedProvider("openai"),
+		},
+	}
+
+	router, err := newRouter(c, providers)
+	if err != nil {
+		t.Fatalf("newRouter() error: %v", err)
+	}
+
+	tests := []struct {
+		templateType  promptgen.TemplateType
+		wantProvider  string
+		wantModel     string
+		wantMaxTokens int
+	}{
+		{promptgen.SelectArticles, "openai", "gpt-4o-mini", 0},
+		{promptgen.ExtractKeywords, "replicate", "meta/meta-llama-3-8b-instruct", 20},
+		{promptgen.GenerateNewsForecast, "replicate", "meta/meta-llama-3-70b-instruct", 0},
+	}
+
+	for _, tt := range tests {
+		route := router.Route(tt.templateType)
+		if route.Provider != namedProvider(tt.wantProvider) || route.Model != tt.wantModel || route.MaxTokens != tt.wantMaxTokens {
+			t.Errorf("%s routed to %v with model %q and %d max tokens, want %s, %q and %d", tt.templateType, route.Provider, route.Model, route.MaxTokens, tt.wantProvider, tt.wantModel, tt.wantMaxTokens)
+		}
+		if route.Temperature != &warm {
+			t.Errorf("%s has temperature %v, want the default", tt.templateType, route.Temperature)
+		}
+	}
+
+	unknown := *c.LLMRoutes
+	unknown.Routes = map[string]config.LLMRouteConfig{"summarize": {Model: "gpt-4o-mini"}}
+	if _, err := newRouter(&config.SystemConfig{EnvConfig: c.EnvConfig, PromptTemplate: c.PromptTemplate, LLMRoutes: &unknown}, providers); err == nil {
+		t.Error("newRouter() accepted a route for a template that doesn't exist")
+	}
+
+	forecaster, err := newForecaster(c, providers, router, nil, ensemble.NewMatcher(nil))
+	if err != nil {
+		t.Fatalf("newForecaster() error: %v", err)
+	}
+	ensembleService := forecaster.(*ensemble.Service)
+	if len(ensembleService.Members) != 2 {
+		t.Fatalf("got %d members, want 2", len(ensembleService.Members))
+	}
+	for i, want := range []string{"gpt-4o", "meta/meta-llama-3-70b-instruct"} {
+		member := ensembleService.Members[i]
+		if member.Name != want {
+			t.Errorf("member %d is %q, want %q", i+1, member.Name, want)
+		}
+
+		// Members only differ in the forecast routes
+		memberRouter := member.Service.(*llm.PromptService).Provider.(*llm.Router)
+		if got := memberRouter.Route(promptgen.GenerateNewsForecast).Model; got != want {
+			t.Errorf("member %d forecasts with %q, want %q", i+1, got, want)
+		}
+		if got := memberRouter.Route(promptgen.SelectArticles).Model; got != "gpt-4o-mini" {
+			t.Errorf("member %d selects articles with %q, want the routed gpt-4o-mini", i+1, got)
+		}
+	}
+}
+
+func TestNewSampledForecaster(t *testing.T) {
+	zero := 0.0
+	c := &config.SystemConfig{
+		EnvConfig:      &config.EnvConfig{LLMConfig: &config.LLMConfig{Provider: "replicate", Samples: 3}},
+		PromptTemplate: loadPrompts(t),
+		LLMRoutes: &config.LLMRoutesConfig{
+			Default: config.LLMRouteConfig{Model: "meta/meta-llama-3-70b-instruct", Temperature: &zero},
+		},
+	}
+	providers := &llmProviders{c: c, providers: map[string]llm.Provider{"replicate": namedProvider("replicate")}}
+
+	router, err := newRouter(c, providers)
+	if err != nil {
+		t.Fatalf("newRouter() error: %v", err)
+	}
+	forecaster, err := newForecaster(c, providers, router, nil, ensemble.NewMatcher(nil))
+	if err != nil {
+		t.Fatalf("newForecaster() error: %v", err)
+	}
+
+	sampler, ok := forecaster.(*ensemble.Sampler)
+	if !ok || sampler.Samples != 3 {
+		t.Fatalf("newForecaster() = %#v, want a sampler of 3", forecaster)
+	}
+
+	// Samples are drawn warm, everything else keeps the configured temperature
+	sampleRouter := sampler.Forecaster.(*llm.PromptService).Provider.(*llm.Router)
+	if temperature := sampleRouter.Route(promptgen.GenerateNewsForecast).Temperature; temperature == nil || *temperature != ensemble.DefaultSampleTemperature {
+		t.Errorf("forecast temperature = %v, want %v", temperature, ensemble.DefaultSampleTemperature)
+	}
+	if temperature := sampleRouter.Route(promptgen.SelectArticles).Temperature; temperature != &zero {
+		t.Errorf("select_articles temperature = %v, want the configured 0", temperature)
+	}
+
+	// An explicit 0 is kept rather than replaced by the default
+	c.EnvConfig.LLMConfig.SampleTemperature = &zero
+	sampler = newSampledForecaster(c, router, nil, ensemble.NewMatcher(nil)).(*ensemble.Sampler)
+	sampleRouter = sampler.Forecaster.(*llm.PromptService).Provider.(*llm.Router)
+	if temperature := sampleRouter.Route(promptgen.GenerateNewsForecast).Temperature; temperature == nil || *temperature != 0 {
+		t.Errorf("forecast temperature = %v, want the configured 0", temperature)
+	}
+}
+
+func TestStubProvider(t *testing.T) {
+	c := &config.SystemConfig{
+		EnvConfig:      &config.EnvConfig{LLMConfig: &config.LLMConfig{Provider: "stub", Samples: 2}},
+		PromptTemplate: loadPrompts(t),
+		LLMRoutes: &config.LLMRoutesConfig{
+			Routes: map[string]config.LLMRouteConfig{
+				"extract_keywords": {Provider: "stub", MaxTokens: 20},
+			},
+		},
+	}
+	providers := &llmProviders{c: c, providers: map[string]llm.Provider{}}
+
+	router, err := newRouter(c, providers)
+	if err != nil {
+		t.Fatalf("newRouter() error: %v", err)
+	}
+
+	// The stub is wrapped like any other provider
+	for _, templateType := range []promptgen.TemplateType{promptgen.ExtractKeywords, promptgen.GenerateNewsForecast} {
+		provider, ok := router.Route(templateType).Provider.(*usage.Provider)
+		if !ok {
+			t.Fatalf("%s routed to %T, want a recording provider", templateType, router.Route(templateType).Provider)
+		}
+		if _, ok := provider.Next.(*stub.Service); !ok {
+			t.Errorf("%s is recorded for %T, want the stub", templateType, provider.Next)
+		}
+	}
+
+	forecaster, err := newForecaster(c, providers, router, nil, ensemble.NewMatcher(nil))
+	if err != nil {
+		t.Fatalf("newForecaster() error: %v", err)
+	}
+	if _, ok := forecaster.(*ensemble.Sampler); !ok {
+		t.Errorf("newForecaster() = %#v, want a sampler over the stub", forecaster)
+	}
+}
+
+func TestNameMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		configs []config.LLMMemberConfig
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "distinct models",
+			names:   []string{"gpt-4o", "llama"},
+			configs: make([]config.LLMMemberConfig, 2),
+			want:    []string{"gpt-4o", "llama"},
+		},
+		{
+			name:    "shared model",
+			names:   []string{"gpt-4o", "llama", "gpt-4o"},
+			configs: make([]config.LLMMemberConfig, 3),
+			want:    []string{"gpt-4o #1", "llama", "gpt-4o #3"},
+		},
+		{
+			name:    "configured name",
+			names:   []string{"gpt-4o", "gpt-4o"},
+			configs: []config.LLMMemberConfig{{Name: "gpt-4o"}, {}},
+			want:    []string{"gpt-4o", "gpt-4o #2"},
+		},
+		{
+			name:    "configured twice",
+			names:   []string{"warm", "warm"},
+			configs: []config.LLMMemberConfig{{Name: "warm"}, {Name: "warm"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := make([]ensemble.Member, len(tt.names))
+			for i, name := range tt.names {
+				members[i].Name = name
+			}
+
+			err := nameMembers(members, tt.configs)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("nameMembers() accepted a name used twice")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nameMembers() error: %v", err)
+			}
+			for i, want := range tt.want {
+				if members[i].Name != want {
+					t.Errorf("member %d is %q, want %q", i+1, members[i].Name, want)
+				}
+			}
+		})
+	}
+}
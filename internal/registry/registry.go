@@ -1,12 +1,17 @@
 package registry
 
 import (
+	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/qoentz/evedict/config"
 	"github.com/qoentz/evedict/internal/db/repository"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/eventfeed/gdelt"
 	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
 	"github.com/qoentz/evedict/internal/eventfeed/polymarket"
-	"github.com/qoentz/evedict/internal/llm/replicate"
+	"github.com/qoentz/evedict/internal/eventfeed/rss"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/llm/translate"
 	"github.com/qoentz/evedict/internal/service"
 	"log"
 )
@@ -14,24 +19,55 @@ import (
 type Registry struct {
 	AuthService       *service.AuthService
 	ForecastService   *service.ForecastService
-	ReplicateService  *replicate.Service
+	AIService         llm.Service
 	NewsAPIService    *newsapi.Service
 	PolyMarketService *polymarket.Service
 	MailService       *service.MailService
+	UsageService      *service.UsageService
+	PromptService     *service.PromptService
 }
 
-func NewRegistry(c *config.SystemConfig, db *sqlx.DB) *Registry {
+func NewRegistry(c *config.SystemConfig, db *sqlx.DB) (*Registry, error) {
 	authService := service.NewAuthService(c.EnvConfig.AuthSecret)
 
 	forecastRepository := repository.NewForecastRepository(db)
 
-	replicateService := replicate.NewReplicateService(c.HTTPClient, c.PromptTemplate, c.EnvConfig.ExternalServiceConfig.ReplicateModel, c.EnvConfig.ExternalServiceConfig.ReplicateAPIKey)
+	embedder, err := newEmbedder(c)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring embedding provider: %v", err)
+	}
+
+	promptService := service.NewPromptService(c.PromptTemplate, c.PromptsPath, repository.NewForecastAttemptRepository(db))
+
+	aiService, forecaster, err := newAIService(c, db, embedder, promptService)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring LLM provider: %v", err)
+	}
+
 	newsAPIService := newsapi.NewNewsAPIService(c.HTTPClient, c.EnvConfig.ExternalServiceConfig.NewsAPIKey, c.EnvConfig.ExternalServiceConfig.NewsAPIURL)
 
 	polyMarketService := polymarket.NewPolyMarketService(c.HTTPClient, c.EnvConfig.ExternalServiceConfig.PolyMarketBaseURL)
 
-	marketService := service.NewMarketService(polyMarketService, replicateService)
-	forecastService := service.NewForecastService(forecastRepository, replicateService, newsAPIService, marketService)
+	articleSource := eventfeed.NewMultiSource(newsAPIService)
+	if gdeltURL := c.EnvConfig.ExternalServiceConfig.GDELTURL; gdeltURL != "" {
+		articleSource.Sources = append(articleSource.Sources, gdelt.NewGDELTService(c.HTTPClient, gdeltURL, gdelt.Query{
+			SourceLang:    c.EnvConfig.ExternalServiceConfig.GDELTSourceLang,
+			SourceCountry: c.EnvConfig.ExternalServiceConfig.GDELTSourceCountry,
+		}))
+	}
+	if len(c.RSSFeeds) > 0 {
+		articleSource.Sources = append(articleSource.Sources, rss.NewRSSService(c.HTTPClient, c.RSSFeeds))
+	}
+
+	marketService := service.NewMarketService(polyMarketService, aiService)
+	minRelatedSimilarity := service.DefaultMinRelatedSimilarity
+	if v := c.EnvConfig.LLMConfig.EmbeddingMinSimilarity; v != nil {
+		minRelatedSimilarity = *v
+	}
+
+	forecastService := service.NewForecastService(forecastRepository, aiService, articleSource, marketService, translate.NewTranslator(aiService), forecaster, embedder, minRelatedSimilarity)
+
+	usageService := service.NewUsageService(repository.NewLLMCallRepository(db))
 
 	mailService, err := service.NewMailService(c.EnvConfig.AWSConfig.SESAccessKey, c.EnvConfig.AWSConfig.SESSecretAccessKey, c.EnvConfig.AWSConfig.Region)
 	if err != nil {
@@ -41,9 +77,11 @@ func NewRegistry(c *config.SystemConfig, db *sqlx.DB) *Registry {
 	return &Registry{
 		AuthService:       authService,
 		ForecastService:   forecastService,
-		ReplicateService:  replicateService,
+		AIService:         aiService,
 		NewsAPIService:    newsAPIService,
 		PolyMarketService: polyMarketService,
 		MailService:       mailService,
-	}
+		UsageService:      usageService,
+		PromptService:     promptService,
+	}, nil
 }
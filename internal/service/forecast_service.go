@@ -1,61 +1,93 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/qoentz/evedict/internal/api/dto"
 	"github.com/qoentz/evedict/internal/db/model"
 	"github.com/qoentz/evedict/internal/db/repository"
-	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
-	"github.com/qoentz/evedict/internal/eventfeed/polymarket"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"github.com/qoentz/evedict/internal/llm"
-	"github.com/qoentz/evedict/internal/llm/replicate"
+	"github.com/qoentz/evedict/internal/llm/ensemble"
+	"github.com/qoentz/evedict/internal/llm/translate"
 	"github.com/qoentz/evedict/internal/promptgen"
 	"github.com/qoentz/evedict/internal/util"
 )
 
+const (
+	relatedLimit = 4
+	// relatedCandidates is how many of the newest approved forecasts are
+	// ranked by similarity on a page view
+	relatedCandidates = 500
+	// DefaultMinRelatedSimilarity keeps forecasts that merely share a
+	// category out of the semantic matches. Embedding models differ in how
+	// similar unrelated texts come out, so it can be configured.
+	DefaultMinRelatedSimilarity = 0.3
+)
+
 type ForecastService struct {
 	ForecastRepository *repository.ForecastRepository
 	AIService          llm.Service
-	NewsAPIService     *newsapi.Service
+	ArticleSource      eventfeed.Source
 	MarketService      *MarketService
+	Translator         *translate.Translator
+	// Embedder enables semantic related forecasts when set
+	Embedder llm.Embedder
+	// MinRelatedSimilarity is the similarity from which forecasts count as
+	// related under the model of the Embedder
+	MinRelatedSimilarity float64
+	// Forecaster replaces AIService for forecast generation when set, e.g.
+	// with an ensemble of models or self-consistency sampling
+	Forecaster ensemble.Forecaster
 }
 
-func NewForecastService(forecastRepository *repository.ForecastRepository, replicateService *replicate.Service, newsAPIService *newsapi.Service, marketService *MarketService) *ForecastService {
+func NewForecastService(forecastRepository *repository.ForecastRepository, aiService llm.Service, articleSource eventfeed.Source, marketService *MarketService, translator *translate.Translator, forecaster ensemble.Forecaster, embedder llm.Embedder, minRelatedSimilarity float64) *ForecastService {
 	return &ForecastService{
-		ForecastRepository: forecastRepository,
-		AIService:          replicateService,
-		NewsAPIService:     newsAPIService,
-		MarketService:      marketService,
+		ForecastRepository:   forecastRepository,
+		AIService:            aiService,
+		ArticleSource:        articleSource,
+		MarketService:        marketService,
+		Translator:           translator,
+		Forecaster:           forecaster,
+		Embedder:             embedder,
+		MinRelatedSimilarity: minRelatedSimilarity,
 	}
 }
 
-func (s *ForecastService) GeneratePolyForecasts() ([]dto.Forecast, error) {
-	selectedEvents, err := s.MarketService.GetMarketEvents(2)
+func (s *ForecastService) GeneratePolyForecasts(ctx context.Context) ([]dto.Forecast, error) {
+	ctx = llm.WithRunID(ctx, uuid.New())
+
+	selectedEvents, err := s.MarketService.GetMarketEvents(ctx, 2)
 	if err != nil {
 		return nil, err
 	}
 
 	var forecasts []dto.Forecast
 	for _, e := range selectedEvents {
+		// Per-event errors are skipped below, but a cancelled request should stop the run
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		var keywords []string
 		for _, tag := range e.Tags {
 			keywords = append(keywords, tag.Label)
 		}
 
-		articles, err := s.NewsAPIService.FetchWithKeywords(keywords)
+		articles, err := s.ArticleSource.FetchWithKeywords(ctx, keywords)
 		if err != nil {
-			return nil, fmt.Errorf("error fetching articles from NewsAPI: %v", err)
+			return nil, fmt.Errorf("error fetching articles from %s: %v", s.ArticleSource.Name(), err)
 		}
+		articles = screenArticles(s.Translator.TranslateArticles(ctx, articles))
 
-		mainArticleIdx, err := s.AIService.SelectIndex(promptgen.SelectArticleForEvent, struct {
-			Event    polymarket.Event
-			Articles []newsapi.Article
-		}{Event: e, Articles: articles})
+		mainArticleIdx, err := s.AIService.SelectIndex(ctx, promptgen.SelectArticleForEvent, promptgen.SelectArticleForEventData{Event: e, Articles: articles})
 		if err != nil {
 			log.Printf("Error selecting article for event %s: %v", e.Title, err)
 			continue
@@ -68,12 +100,12 @@ func (s *ForecastService) GeneratePolyForecasts() ([]dto.Forecast, error) {
 
 		mainArticle := articles[mainArticleIdx]
 
-		if exists, _ := s.ForecastRepository.CheckImageURL(mainArticle.URLToImage); exists {
+		if s.alreadyForecast(mainArticle) {
 			log.Println(mainArticle.Title + " already exists!")
 			continue
 		}
 
-		forecast, err := s.AIService.GetForecast(mainArticle, articles, &e)
+		forecast, err := s.getForecast(ctx, mainArticle, articles, &e)
 		if err != nil {
 			log.Printf("Error generating forecast for event %s: %v", e.Title, err)
 			continue
@@ -81,6 +113,7 @@ func (s *ForecastService) GeneratePolyForecasts() ([]dto.Forecast, error) {
 
 		s.MarketService.AttachMarketData(e, forecast)
 		s.attachMetadata(mainArticle, forecast, keywords, articles)
+		s.attachEmbedding(ctx, forecast)
 
 		forecasts = append(forecasts, *forecast)
 	}
@@ -88,52 +121,128 @@ func (s *ForecastService) GeneratePolyForecasts() ([]dto.Forecast, error) {
 	return forecasts, nil
 }
 
-func (s *ForecastService) GenerateForecasts(category newsapi.Category) ([]dto.Forecast, error) {
-	headlines, err := s.NewsAPIService.FetchTopHeadlines(category)
+func (s *ForecastService) GenerateForecasts(ctx context.Context, category eventfeed.Category) ([]dto.Forecast, error) {
+	ctx = llm.WithRunID(ctx, uuid.New())
+
+	headlines, err := s.ArticleSource.FetchTopHeadlines(ctx, category)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching headlines from NewsAPI: %v", err)
+		return nil, fmt.Errorf("error fetching headlines from %s: %v", s.ArticleSource.Name(), err)
+	}
+	headlines = titledHeadlines(screenArticles(s.Translator.TranslateArticles(ctx, headlines)))
+	if len(headlines) == 0 {
+		return nil, fmt.Errorf("no usable headlines from %s", s.ArticleSource.Name())
 	}
 
-	articleSelection, err := s.AIService.SelectIndexes(promptgen.SelectArticles, struct {
-		Articles []newsapi.Article
-	}{Articles: headlines}, 2)
+	articleSelection, err := s.AIService.SelectIndexes(ctx, promptgen.SelectArticles, promptgen.SelectArticlesData{Articles: headlines}, 2)
 	if err != nil {
 		return nil, fmt.Errorf("error selecting markets: %v", err)
 	}
 
 	var forecasts []dto.Forecast
+	var lastErr error
 	for _, idx := range articleSelection {
+		// Per-article errors are skipped below, but a cancelled request should stop the run
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		mainArticle := headlines[idx]
 
-		exists, _ := s.ForecastRepository.CheckImageURL(mainArticle.URLToImage)
-		if exists {
+		if s.alreadyForecast(mainArticle) {
 			log.Println(mainArticle.Title + " already exists!")
 			continue
 		}
 
-		keywords, err := s.AIService.ExtractKeywords(mainArticle)
+		keywords, err := s.AIService.ExtractKeywords(ctx, mainArticle)
 		if err != nil {
-			return nil, fmt.Errorf("error extracting keywords: %v", err)
+			lastErr = fmt.Errorf("error extracting keywords: %v", err)
+			log.Printf("Error extracting keywords for article %s: %v", mainArticle.Title, err)
+			continue
 		}
 
-		articles, err := s.NewsAPIService.FetchWithKeywords(keywords)
+		articles, err := s.ArticleSource.FetchWithKeywords(ctx, keywords)
 		if err != nil {
-			return nil, fmt.Errorf("error fetching articles from NewsAPI with keywords: %v", err)
+			lastErr = fmt.Errorf("error fetching articles from %s with keywords: %v", s.ArticleSource.Name(), err)
+			log.Printf("Error fetching articles from %s for article %s: %v", s.ArticleSource.Name(), mainArticle.Title, err)
+			continue
 		}
+		articles = screenArticles(s.Translator.TranslateArticles(ctx, articles))
 
-		forecast, err := s.AIService.GetForecast(mainArticle, articles, nil)
+		forecast, err := s.getForecast(ctx, mainArticle, articles, nil)
 		if err != nil {
-			return nil, fmt.Errorf("error generating forecast: %v", err)
+			lastErr = fmt.Errorf("error generating forecast: %v", err)
+			log.Printf("Error generating forecast for article %s: %v", mainArticle.Title, err)
+			continue
 		}
 
 		s.attachMetadata(mainArticle, forecast, keywords, articles)
+		s.attachEmbedding(ctx, forecast)
 		forecasts = append(forecasts, *forecast)
 	}
 
+	// Articles that were forecast before are not a failure, but a run in
+	// which every other article failed is
+	if len(forecasts) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
 	return forecasts, nil
 }
 
-func (s *ForecastService) attachMetadata(mainArticle newsapi.Article, forecast *dto.Forecast, keywords []string, articles []newsapi.Article) {
+// titledHeadlines keeps the headlines a forecast can be built on. The
+// forecast prompt needs a title; the description and image may be missing.
+func titledHeadlines(headlines []eventfeed.Article) []eventfeed.Article {
+	result := make([]eventfeed.Article, 0, len(headlines))
+	for _, headline := range headlines {
+		if headline.Title == "" {
+			continue
+		}
+		result = append(result, headline)
+	}
+	return result
+}
+
+// alreadyForecast reports whether an approved forecast was made from the
+// article, matched by its image. Articles without an image never match.
+func (s *ForecastService) alreadyForecast(article eventfeed.Article) bool {
+	if article.URLToImage == "" {
+		return false
+	}
+	exists, _ := s.ForecastRepository.CheckImageURL(article.URLToImage)
+	return exists
+}
+
+// screenArticles drops articles whose text clearly reads like instructions to
+// the model, so a single hostile article can neither become the main article
+// nor be cited. Weaker matches are only logged. Translation runs first, so
+// payloads in other languages are caught too.
+func screenArticles(articles []eventfeed.Article) []eventfeed.Article {
+	result := make([]eventfeed.Article, 0, len(articles))
+	for _, article := range articles {
+		flags := promptgen.InspectArticle(article)
+		if blocking := promptgen.Blocking(flags); len(blocking) > 0 {
+			log.Printf("Dropping suspicious article %q from %s (%s), matched %v", article.Title, article.Source.Name, article.URL, blocking)
+			continue
+		}
+		if len(flags) > 0 {
+			log.Printf("Keeping article %q from %s (%s) despite matching %v", article.Title, article.Source.Name, article.URL, flags)
+		}
+		result = append(result, article)
+	}
+	return result
+}
+
+func (s *ForecastService) getForecast(ctx context.Context, mainArticle eventfeed.Article, articles []eventfeed.Article, event *eventfeed.Event) (*dto.Forecast, error) {
+	// Samples and ensemble members of one forecast share the prompt variant
+	ctx = llm.WithVariants(ctx)
+
+	if s.Forecaster != nil {
+		return s.Forecaster.GetForecast(ctx, mainArticle, articles, event)
+	}
+	return s.AIService.GetForecast(ctx, mainArticle, articles, event)
+}
+
+func (s *ForecastService) attachMetadata(mainArticle eventfeed.Article, forecast *dto.Forecast, keywords []string, articles []eventfeed.Article) {
 	forecast.ImageURL = mainArticle.URLToImage
 	forecast.Timestamp = time.Now().UTC()
 
@@ -150,10 +259,18 @@ func (s *ForecastService) attachMetadata(mainArticle newsapi.Article, forecast *
 		ImageURL: mainArticle.URLToImage,
 	})
 
-	for _, article := range articles {
-		if article.URL == mainArticle.URL || article.Title == "[Removed]" {
+	// Position in sources of each related article, for the outcome citations
+	sourceIndex := make(map[int]int, len(articles))
+
+	for i, article := range articles {
+		if article.URL == mainArticle.URL {
+			sourceIndex[i] = 0
 			continue
 		}
+		if article.Title == "[Removed]" {
+			continue
+		}
+		sourceIndex[i] = len(sources)
 		sources = append(sources, dto.Source{
 			Name:     article.Source.Name,
 			Title:    article.Title,
@@ -163,6 +280,86 @@ func (s *ForecastService) attachMetadata(mainArticle newsapi.Article, forecast *
 	}
 
 	forecast.Sources = sources
+
+	for i, o := range forecast.Outcomes {
+		var cited []int
+		seen := map[int]bool{}
+		for _, idx := range o.SourceIndexes {
+			if pos, ok := sourceIndex[idx]; ok && !seen[pos] {
+				cited = append(cited, pos)
+				seen[pos] = true
+			}
+		}
+		forecast.Outcomes[i].SourceIndexes = cited
+	}
+}
+
+// attachEmbedding is best effort: a forecast without an embedding is still
+// saved and only misses out on semantic related forecasts.
+func (s *ForecastService) attachEmbedding(ctx context.Context, forecast *dto.Forecast) {
+	if s.Embedder == nil {
+		return
+	}
+
+	vectors, err := s.Embedder.Embed(ctx, []string{embeddingText(forecast.Headline, forecast.Summary, forecast.Outcomes)})
+	if err != nil {
+		log.Printf("Error embedding forecast %q: %v", forecast.Headline, err)
+		return
+	}
+
+	forecast.Embedding = vectors[0]
+	forecast.EmbeddingModel = s.Embedder.ModelName()
+}
+
+// BackfillEmbeddings embeds up to limit forecasts saved without an embedding
+// from the current model and returns how many were embedded.
+func (s *ForecastService) BackfillEmbeddings(ctx context.Context, limit int) (int, error) {
+	if s.Embedder == nil {
+		return 0, nil
+	}
+
+	ids, err := s.ForecastRepository.GetForecastIDsWithoutEmbedding(s.Embedder.ModelName(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var embedded int
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return embedded, err
+		}
+
+		forecast, err := s.ForecastRepository.GetForecast(id)
+		if err != nil {
+			return embedded, err
+		}
+
+		outcomes := s.convertToDTO(forecast).Outcomes
+		vectors, err := s.Embedder.Embed(ctx, []string{embeddingText(forecast.Headline, forecast.Summary, outcomes)})
+		if err != nil {
+			return embedded, fmt.Errorf("error embedding forecast %s: %v", id, err)
+		}
+
+		err = s.ForecastRepository.SaveForecastEmbedding(id, &model.ForecastEmbedding{
+			Model:     s.Embedder.ModelName(),
+			Embedding: vectors[0],
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return embedded, err
+		}
+		embedded++
+	}
+
+	return embedded, nil
+}
+
+func embeddingText(headline, summary string, outcomes []dto.Outcome) string {
+	parts := []string{headline, summary}
+	for _, o := range outcomes {
+		parts = append(parts, o.Content)
+	}
+	return strings.Join(parts, "\n")
 }
 
 func (s *ForecastService) GetForecasts(limit int, offset int, category *util.Category) ([]dto.Forecast, error) {
@@ -216,7 +413,7 @@ func (s *ForecastService) GetForecast(forecastID uuid.UUID) (*dto.Forecast, erro
 		tagNames[i] = t.Name
 	}
 
-	relatedForecasts, err := s.ForecastRepository.GetRelatedForecastsByTagAndCategory(forecast.ID, tagNames, forecast.Category, 4)
+	relatedForecasts, err := s.getRelatedForecasts(forecast, tagNames)
 	if err != nil {
 		return nil, err
 	}
@@ -238,6 +435,83 @@ func (s *ForecastService) GetForecast(forecastID uuid.UUID) (*dto.Forecast, erro
 	return dtoForecast, nil
 }
 
+// getRelatedForecasts ranks approved forecasts by embedding similarity and
+// tops the list up with tag and category matches when there are too few.
+func (s *ForecastService) getRelatedForecasts(forecast *model.Forecast, tagNames []string) ([]model.RelatedForecast, error) {
+	related, err := s.getSemanticRelatedForecasts(forecast.ID)
+	if err != nil {
+		// The tag and category match still gives a usable page
+		log.Printf("Error finding semantic related forecasts for %s: %v", forecast.ID, err)
+	}
+	if len(related) >= relatedLimit {
+		return related, nil
+	}
+
+	fallback, err := s.ForecastRepository.GetRelatedForecastsByTagAndCategory(forecast.ID, tagNames, forecast.Category, relatedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(related))
+	for _, r := range related {
+		seen[r.ID] = true
+	}
+	for _, r := range fallback {
+		if len(related) == relatedLimit {
+			break
+		}
+		if !seen[r.ID] {
+			related = append(related, r)
+		}
+	}
+
+	return related, nil
+}
+
+func (s *ForecastService) getSemanticRelatedForecasts(forecastID uuid.UUID) ([]model.RelatedForecast, error) {
+	if s.Embedder == nil {
+		return nil, nil
+	}
+
+	modelName := s.Embedder.ModelName()
+
+	embedding, err := s.ForecastRepository.GetForecastEmbedding(forecastID, modelName)
+	if err != nil || embedding == nil {
+		return nil, err
+	}
+
+	candidates, err := s.ForecastRepository.GetApprovedEmbeddings(modelName, forecastID, relatedCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		id    uuid.UUID
+		score float64
+	}
+	var matches []scored
+	for _, c := range candidates {
+		score := llm.CosineSimilarity(embedding.Embedding, c.Embedding)
+		if score >= s.MinRelatedSimilarity {
+			matches = append(matches, scored{id: c.ForecastID, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	if len(matches) > relatedLimit {
+		matches = matches[:relatedLimit]
+	}
+
+	ids := make([]uuid.UUID, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+
+	return s.ForecastRepository.GetRelatedForecastsByIDs(ids)
+}
+
 func (s *ForecastService) SavePolyForecasts(forecasts []dto.Forecast) error {
 	modelForecasts := s.convertToModel(forecasts)
 	err := s.ForecastRepository.SavePolyForecasts(modelForecasts)
@@ -269,12 +543,36 @@ func (s *ForecastService) ApproveForecast(forecastID uuid.UUID) error {
 	return s.ForecastRepository.MarkForecastApproved(forecastID)
 }
 
+func (s *ForecastService) RejectForecast(forecastID uuid.UUID) error {
+	return s.ForecastRepository.MarkForecastRejected(forecastID)
+}
+
 func (s *ForecastService) convertToDTO(forecast *model.Forecast) *dto.Forecast {
+	sourceIndex := make(map[uuid.UUID]int, len(forecast.Sources))
+	for i, src := range forecast.Sources {
+		sourceIndex[src.ID] = i
+	}
+
 	dtoOutcomes := make([]dto.Outcome, len(forecast.Outcomes))
 	for i, o := range forecast.Outcomes {
 		dtoOutcomes[i] = dto.Outcome{
 			Content:         o.Content,
 			ConfidenceLevel: o.ConfidenceLevel,
+			Rationale:       o.Rationale,
+		}
+
+		for _, sourceID := range o.SourceIDs {
+			if idx, ok := sourceIndex[sourceID]; ok {
+				dtoOutcomes[i].SourceIndexes = append(dtoOutcomes[i].SourceIndexes, idx)
+			}
+		}
+
+		for _, c := range o.Contributions {
+			dtoOutcomes[i].Contributions = append(dtoOutcomes[i].Contributions, dto.Contribution{
+				Model:           c.Model,
+				Content:         c.Content,
+				ConfidenceLevel: c.ConfidenceLevel,
+			})
 		}
 	}
 
@@ -312,19 +610,38 @@ func (s *ForecastService) convertToDTO(forecast *model.Forecast) *dto.Forecast {
 		_ = ParseOutcomesAndPrices(dtoMarket)
 	}
 
+	var dtoProvenance *dto.Provenance
+	if forecast.TemplateType != nil {
+		dtoProvenance = &dto.Provenance{
+			TemplateType:  *forecast.TemplateType,
+			PromptVersion: valueOrEmpty(forecast.PromptVersion),
+			PromptHash:    valueOrEmpty(forecast.PromptHash),
+			PromptVariant: valueOrEmpty(forecast.PromptVariant),
+			Model:         valueOrEmpty(forecast.Model),
+		}
+	}
+
 	return &dto.Forecast{
-		ID:        forecast.ID,
-		Headline:  forecast.Headline,
-		Summary:   forecast.Summary,
-		Outcomes:  dtoOutcomes,
-		ImageURL:  forecast.ImageURL,
-		Tags:      dtoTags,
-		Sources:   dtoSources,
-		Timestamp: forecast.Timestamp,
-		Market:    dtoMarket,
+		ID:         forecast.ID,
+		Headline:   forecast.Headline,
+		Summary:    forecast.Summary,
+		Outcomes:   dtoOutcomes,
+		ImageURL:   forecast.ImageURL,
+		Tags:       dtoTags,
+		Sources:    dtoSources,
+		Timestamp:  forecast.Timestamp,
+		Market:     dtoMarket,
+		Provenance: dtoProvenance,
 	}
 }
 
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func (s *ForecastService) convertToModel(forecasts []dto.Forecast) []model.Forecast {
 	modelForecasts := make([]model.Forecast, len(forecasts))
 
@@ -332,14 +649,50 @@ func (s *ForecastService) convertToModel(forecasts []dto.Forecast) []model.Forec
 		// Generate a UUID for the forecast
 		forecastID := uuid.New()
 
+		// Generate UUIDs and set ForecastID for associated Sources
+		sources := make([]model.Source, len(forecast.Sources))
+		for k, src := range forecast.Sources {
+			sources[k] = model.Source{
+				ID:         uuid.New(), // New UUID for each source
+				ForecastID: forecastID,
+				Name:       src.Name,
+				Title:      src.Title,
+				URL:        src.URL,
+				ImageURL:   &src.ImageURL,
+			}
+		}
+
 		// Generate UUIDs and set ForecastID for associated Outcomes
 		outcomes := make([]model.Outcome, len(forecast.Outcomes))
 		for j, o := range forecast.Outcomes {
+			outcomeID := uuid.New() // New UUID for each outcome
+
+			contributions := make([]model.OutcomeContribution, len(o.Contributions))
+			for k, c := range o.Contributions {
+				contributions[k] = model.OutcomeContribution{
+					ID:              uuid.New(),
+					OutcomeID:       outcomeID,
+					Model:           c.Model,
+					Content:         c.Content,
+					ConfidenceLevel: c.ConfidenceLevel,
+				}
+			}
+
+			var sourceIDs []uuid.UUID
+			for _, idx := range o.SourceIndexes {
+				if idx >= 0 && idx < len(sources) {
+					sourceIDs = append(sourceIDs, sources[idx].ID)
+				}
+			}
+
 			outcomes[j] = model.Outcome{
-				ID:              uuid.New(), // New UUID for each outcome
+				ID:              outcomeID,
 				ForecastID:      forecastID,
 				Content:         o.Content,
 				ConfidenceLevel: o.ConfidenceLevel,
+				Rationale:       o.Rationale,
+				SourceIDs:       sourceIDs,
+				Contributions:   contributions,
 			}
 		}
 
@@ -350,16 +703,12 @@ func (s *ForecastService) convertToModel(forecasts []dto.Forecast) []model.Forec
 			}
 		}
 
-		// Generate UUIDs and set ForecastID for associated Sources
-		sources := make([]model.Source, len(forecast.Sources))
-		for k, src := range forecast.Sources {
-			sources[k] = model.Source{
-				ID:         uuid.New(), // New UUID for each source
-				ForecastID: forecastID,
-				Name:       src.Name,
-				Title:      src.Title,
-				URL:        src.URL,
-				ImageURL:   &src.ImageURL,
+		var embedding *model.ForecastEmbedding
+		if forecast.Embedding != nil {
+			embedding = &model.ForecastEmbedding{
+				Model:     forecast.EmbeddingModel,
+				Embedding: forecast.Embedding,
+				CreatedAt: forecast.Timestamp,
 			}
 		}
 
@@ -387,6 +736,17 @@ func (s *ForecastService) convertToModel(forecasts []dto.Forecast) []model.Forec
 			Tags:      tags,
 			Sources:   sources,
 			Market:    market,
+			Embedding: embedding,
+		}
+
+		if p := forecast.Provenance; p != nil {
+			modelForecasts[i].TemplateType = &p.TemplateType
+			modelForecasts[i].PromptVersion = &p.PromptVersion
+			modelForecasts[i].PromptHash = &p.PromptHash
+			modelForecasts[i].Model = &p.Model
+			if p.PromptVariant != "" {
+				modelForecasts[i].PromptVariant = &p.PromptVariant
+			}
 		}
 	}
 
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/qoentz/evedict/internal/eventfeed"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/llm/stub"
+	"github.com/qoentz/evedict/internal/llm/translate"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"path/filepath"
+	"testing"
+)
+
+// feed is a Source and EventSource with fixed answers.
+type feed struct {
+	headlines []eventfeed.Article
+	related   []eventfeed.Article
+	events    []eventfeed.Event
+	err       error
+}
+
+func (f *feed) Name() string {
+	return "feed"
+}
+
+func (f *feed) FetchTopHeadlines(context.Context, eventfeed.Category) ([]eventfeed.Article, error) {
+	return f.headlines, nil
+}
+
+func (f *feed) FetchWithKeywords(context.Context, []string) ([]eventfeed.Article, error) {
+	return f.related, f.err
+}
+
+func (f *feed) FetchTopEvents(context.Context) ([]eventfeed.Event, error) {
+	return f.events, nil
+}
+
+func newTestFeed() *feed {
+	return &feed{
+		headlines: []eventfeed.Article{
+			{Title: "Zentralbank senkt die Zinsen", Description: "Die Notenbank handelt.", URL: "https://example.com/zinsen", Language: "de"},
+			{Title: "Fed holds rates steady", Description: "Policymakers wait.", URL: "https://example.com/fed", Language: "en"},
+			{Title: "Oil prices fall", URL: "https://example.com/oil", Language: "en"},
+		},
+		related: []eventfeed.Article{
+			{Title: "Markets rally", Description: "Stocks rise.", URL: "https://example.com/markets", Language: "en"},
+			{Title: "Bonds steady", URL: "https://example.com/bonds", Language: "en"},
+		},
+		events: []eventfeed.Event{
+			{ID: "1", Title: "Fed cut in September?", Tags: []eventfeed.Tag{{Label: "Fed"}}, Markets: []eventfeed.Market{{ID: "11", Question: "Will the Fed cut?"}}},
+			{ID: "2", Title: "Two markets", Markets: []eventfeed.Market{{ID: "21"}, {ID: "22"}}},
+			{ID: "3", Title: "Oil above $100?", Tags: []eventfeed.Tag{{Label: "Oil"}}, Markets: []eventfeed.Market{{ID: "31", Question: "Will oil top $100?"}}},
+		},
+	}
+}
+
+// newStubForecastService runs the pipeline on the stub provider. The articles
+// have no image, so the repository is never asked about duplicates.
+func newStubForecastService(t *testing.T, source *feed) *ForecastService {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	writePromptFile(t, path, prompts("Keywords for {{.Title}}"))
+	promptTemplate, err := promptgen.LoadPromptTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+
+	provider, err := stub.NewStubService("", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aiService := llm.NewPromptService(provider, promptTemplate, llm.DefaultMaxAttempts, nil)
+	return NewForecastService(nil, aiService, source, NewMarketService(source, aiService), translate.NewTranslator(aiService), nil, provider, DefaultMinRelatedSimilarity)
+}
+
+func TestGenerateForecasts(t *testing.T) {
+	s := newStubForecastService(t, newTestFeed())
+
+	forecasts, err := s.GenerateForecasts(context.Background(), eventfeed.General)
+	if err != nil {
+		t.Fatalf("GenerateForecasts() error: %v", err)
+	}
+	if len(forecasts) != 2 {
+		t.Fatalf("got %d forecasts, want 2", len(forecasts))
+	}
+
+	for i, want := range []string{"Zentralbank senkt die Zinsen", "Fed holds rates steady"} {
+		f := forecasts[i]
+		if len(f.Sources) != 3 || f.Sources[0].Title != want {
+			t.Errorf("forecast %d has sources %+v, want %q and the 2 related articles", i+1, f.Sources, want)
+		}
+		if len(f.Tags) != 2 {
+			t.Errorf("forecast %d has tags %+v, want 2 keywords", i+1, f.Tags)
+		}
+		if f.Provenance == nil || f.Provenance.Model != "stub" || f.Provenance.TemplateType != string(promptgen.GenerateNewsForecast) {
+			t.Errorf("forecast %d has provenance %+v", i+1, f.Provenance)
+		}
+		if len(f.Embedding) == 0 || f.EmbeddingModel != "stub" {
+			t.Errorf("forecast %d has no stub embedding", i+1)
+		}
+	}
+}
+
+func TestGenerateForecastsFails(t *testing.T) {
+	source := newTestFeed()
+	source.err = errors.New("feed down")
+	s := newStubForecastService(t, source)
+
+	if forecasts, err := s.GenerateForecasts(context.Background(), eventfeed.General); err == nil {
+		t.Errorf("GenerateForecasts() = %d forecasts and no error when every article failed", len(forecasts))
+	}
+}
+
+func TestGeneratePolyForecasts(t *testing.T) {
+	s := newStubForecastService(t, newTestFeed())
+
+	forecasts, err := s.GeneratePolyForecasts(context.Background())
+	if err != nil {
+		t.Fatalf("GeneratePolyForecasts() error: %v", err)
+	}
+	if len(forecasts) != 2 {
+		t.Fatalf("got %d forecasts, want 2", len(forecasts))
+	}
+
+	// The event with two markets is not offered for selection
+	for i, want := range []int64{11, 31} {
+		f := forecasts[i]
+		if f.Market == nil || f.Market.ID != want {
+			t.Errorf("forecast %d has market %+v, want %d", i+1, f.Market, want)
+		}
+		if f.Sources[0].Title != "Markets rally" {
+			t.Errorf("forecast %d is based on %q, want the selected Markets rally", i+1, f.Sources[0].Title)
+		}
+		if f.Provenance == nil || f.Provenance.TemplateType != string(promptgen.GenerateMarketForecast) {
+			t.Errorf("forecast %d has provenance %+v", i+1, f.Provenance)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/qoentz/evedict/internal/api/dto"
-	"github.com/qoentz/evedict/internal/eventfeed/polymarket"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"github.com/qoentz/evedict/internal/llm"
 	"github.com/qoentz/evedict/internal/promptgen"
 	"log"
@@ -11,37 +12,44 @@ import (
 )
 
 type MarketService struct {
-	PolyMarketService *polymarket.Service
-	AIService         llm.Service
+	EventSource eventfeed.EventSource
+	AIService   llm.Service
 }
 
-func NewMarketService(polyMarketService *polymarket.Service, aiService llm.Service) *MarketService {
+func NewMarketService(eventSource eventfeed.EventSource, aiService llm.Service) *MarketService {
 	return &MarketService{
-		PolyMarketService: polyMarketService,
-		AIService:         aiService,
+		EventSource: eventSource,
+		AIService:   aiService,
 	}
 }
 
-func (s *MarketService) GetMarketEvents(num int) ([]polymarket.Event, error) {
-	events, err := s.PolyMarketService.FetchTopEvents()
+func (s *MarketService) GetMarketEvents(ctx context.Context, num int) ([]eventfeed.Event, error) {
+	events, err := s.EventSource.FetchTopEvents(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching events: %v", err)
+		return nil, fmt.Errorf("error fetching events from %s: %v", s.EventSource.Name(), err)
 	}
 
-	var SMPEvents []polymarket.Event
+	var SMPEvents []eventfeed.Event
 	for _, e := range events {
-		if len(e.Markets) == 1 {
-			SMPEvents = append(SMPEvents, e)
+		if len(e.Markets) != 1 {
+			continue
 		}
+		flags := promptgen.InspectEvent(e)
+		if blocking := promptgen.Blocking(flags); len(blocking) > 0 {
+			log.Printf("Dropping suspicious event %q (%s), matched %v", e.Title, e.ID, blocking)
+			continue
+		}
+		if len(flags) > 0 {
+			log.Printf("Keeping event %q (%s) despite matching %v", e.Title, e.ID, flags)
+		}
+		SMPEvents = append(SMPEvents, e)
 	}
-	selectedIndexes, err := s.AIService.SelectIndexes(promptgen.SelectMarkets, struct {
-		Events []polymarket.Event
-	}{Events: SMPEvents}, num)
+	selectedIndexes, err := s.AIService.SelectIndexes(ctx, promptgen.SelectMarkets, promptgen.SelectMarketsData{Events: SMPEvents}, num)
 	if err != nil {
 		return nil, fmt.Errorf("error selecting markets: %v", err)
 	}
 
-	var selectedMarkets []polymarket.Event
+	var selectedMarkets []eventfeed.Event
 	for _, idx := range selectedIndexes {
 		selectedMarkets = append(selectedMarkets, SMPEvents[idx])
 	}
@@ -49,8 +57,8 @@ func (s *MarketService) GetMarketEvents(num int) ([]polymarket.Event, error) {
 	return selectedMarkets, nil
 }
 
-func (s *MarketService) AttachMarketData(event polymarket.Event, forecast *dto.Forecast) {
-	var firstMarket polymarket.Market
+func (s *MarketService) AttachMarketData(event eventfeed.Event, forecast *dto.Forecast) {
+	var firstMarket eventfeed.Market
 	if len(event.Markets) > 0 {
 		firstMarket = event.Markets[0]
 	}
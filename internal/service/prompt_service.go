@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/db/repository"
+	"github.com/qoentz/evedict/internal/llm"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PromptService reloads the shared prompt templates from Path, keeps track of
+// how the last reload went and compares prompt variants.
+type PromptService struct {
+	PromptTemplate            *promptgen.PromptTemplate
+	Path                      string
+	ForecastAttemptRepository *repository.ForecastAttemptRepository
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	lastErr  error
+	failedAt time.Time
+}
+
+func NewPromptService(promptTemplate *promptgen.PromptTemplate, path string, forecastAttemptRepository *repository.ForecastAttemptRepository) *PromptService {
+	return &PromptService{
+		PromptTemplate:            promptTemplate,
+		Path:                      path,
+		ForecastAttemptRepository: forecastAttemptRepository,
+		loadedAt:                  time.Now(),
+	}
+}
+
+var _ llm.AttemptRecorder = &PromptService{}
+
+// SaveForecastAttempt stores a forecast attempt of the llm package, so the
+// prompt variants can be compared.
+func (s *PromptService) SaveForecastAttempt(attempt *llm.ForecastAttempt) error {
+	record := &model.ForecastAttempt{
+		ID:            uuid.New(),
+		RunID:         attempt.RunID,
+		TemplateType:  string(attempt.TemplateType),
+		PromptVariant: attempt.PromptVariant,
+		PromptHash:    attempt.PromptHash,
+		Model:         attempt.Model,
+		Attempt:       attempt.Attempt,
+		Parsed:        attempt.Err == nil,
+		CreatedAt:     attempt.CreatedAt,
+	}
+
+	if attempt.Err != nil {
+		msg := attempt.Err.Error()
+		record.Error = &msg
+	}
+
+	return s.ForecastAttemptRepository.SaveForecastAttempt(record)
+}
+
+// Reload swaps in the prompts at Path if they load and validate. Otherwise
+// the current prompts stay in use and the error is kept for Status.
+func (s *PromptService) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.PromptTemplate.Reload(s.Path); err != nil {
+		log.Printf("Error reloading prompts from %s: %v", s.Path, err)
+		s.lastErr = err
+		s.failedAt = time.Now()
+		return err
+	}
+
+	log.Printf("Reloaded prompts from %s", s.Path)
+	s.lastErr = nil
+	s.loadedAt = time.Now()
+	return nil
+}
+
+func (s *PromptService) Status() *dto.PromptStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &dto.PromptStatus{
+		Path:     s.Path,
+		LoadedAt: s.loadedAt,
+	}
+
+	for _, name := range s.PromptTemplate.Names() {
+		version := s.PromptTemplate.Version(name)
+		status.Prompts = append(status.Prompts, dto.PromptInfo{
+			Name:    string(name),
+			Version: version.Version,
+			Hash:    version.Hash,
+			Weight:  version.Weight,
+		})
+	}
+
+	if s.lastErr != nil {
+		failedAt := s.failedAt
+		status.Error = s.lastErr.Error()
+		status.FailedAt = &failedAt
+	}
+
+	return status
+}
+
+// GetVariantStats reports how the variants of each forecast prompt fare. The
+// approval rate only counts reviewed forecasts.
+func (s *PromptService) GetVariantStats() ([]dto.PromptVariantStats, error) {
+	stats, err := s.ForecastAttemptRepository.GetPromptVariantStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt variant stats: %v", err)
+	}
+
+	result := make([]dto.PromptVariantStats, len(stats))
+	for i, st := range stats {
+		name := promptgen.VariantName(promptgen.TemplateType(st.TemplateType), st.PromptVariant)
+
+		result[i] = dto.PromptVariantStats{
+			TemplateType:  st.TemplateType,
+			Variant:       st.PromptVariant,
+			Loaded:        s.PromptTemplate.Has(name),
+			Weight:        s.PromptTemplate.Version(name).Weight,
+			Attempts:      st.Attempts,
+			ParseFailures: st.ParseFailures,
+			Forecasts:     st.Forecasts,
+			Approved:      st.Approved,
+			Rejected:      st.Rejected,
+		}
+
+		if reviewed := st.Approved + st.Rejected; reviewed > 0 {
+			rate := float64(st.Approved) / float64(reviewed)
+			result[i].ApprovalRate = &rate
+		}
+		if st.Attempts > 0 {
+			rate := float64(st.ParseFailures) / float64(st.Attempts)
+			result[i].ParseFailureRate = &rate
+		}
+	}
+
+	return result, nil
+}
+
+// Watch reloads the prompts whenever the files at Path change, checking every
+// interval until ctx is done.
+func (s *PromptService) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastMod := modTime(s.Path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mod := modTime(s.Path)
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+			_ = s.Reload()
+		}
+	}
+}
+
+// modTime is the latest modification time of path, or of the files in it if
+// it is a directory.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	latest := info.ModTime()
+	if !info.IsDir() {
+		return latest
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return latest
+	}
+	for _, entry := range entries {
+		entryInfo, err := os.Stat(filepath.Join(path, entry.Name()))
+		if err == nil && entryInfo.ModTime().After(latest) {
+			latest = entryInfo.ModTime()
+		}
+	}
+
+	return latest
+}
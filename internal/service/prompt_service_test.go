@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"github.com/qoentz/evedict/internal/promptgen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// prompts defines every template the pipeline uses, with keywords as the
+// extract_keywords template.
+func prompts(keywords string) string {
+	return `
+generate_news_forecast: "{{.MainArticle.Title}}"
+generate_market_forecast: "{{.Event.Title}}"
+select_articles: "{{len .Articles}}"
+select_markets: "{{len .Events}}"
+select_article_for_event: "{{.Event.Title}}"
+extract_keywords: "` + keywords + `"
+`
+}
+
+func writePromptFile(t *testing.T, path, text string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestPromptService(t *testing.T) (*PromptService, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "prompts.yaml")
+	writePromptFile(t, path, prompts("v1 {{.Title}}"))
+
+	promptTemplate, err := promptgen.LoadPromptTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadPromptTemplate() error: %v", err)
+	}
+	return NewPromptService(promptTemplate, path, nil), path
+}
+
+func keywordsPrompt(t *testing.T, s *PromptService) string {
+	t.Helper()
+
+	prompt, err := s.PromptTemplate.CreatePrompt(promptgen.ExtractKeywords, struct{ Title string }{"Fed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prompt
+}
+
+func TestPromptReload(t *testing.T) {
+	s, path := newTestPromptService(t)
+	loadedAt := s.Status().LoadedAt
+
+	writePromptFile(t, path, prompts("v2 {{.Title}}"))
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if got := keywordsPrompt(t, s); got != "v2 Fed" {
+		t.Errorf("prompt after reload = %q, want v2 Fed", got)
+	}
+
+	status := s.Status()
+	if status.Error != "" || status.FailedAt != nil || !status.LoadedAt.After(loadedAt) {
+		t.Errorf("status after reload = %+v", status)
+	}
+
+	// A prompt that doesn't validate leaves the loaded ones in place
+	writePromptFile(t, path, prompts("v3 {{.Headline}}"))
+	if err := s.Reload(); err == nil {
+		t.Fatal("Reload() accepted a prompt with an unknown field")
+	}
+	if got := keywordsPrompt(t, s); got != "v2 Fed" {
+		t.Errorf("prompt after a failed reload = %q, want v2 Fed", got)
+	}
+
+	status = s.Status()
+	if !strings.Contains(status.Error, "Headline") || status.FailedAt == nil {
+		t.Errorf("status after a failed reload = %+v", status)
+	}
+
+	var names []string
+	for _, prompt := range status.Prompts {
+		names = append(names, prompt.Name)
+		if prompt.Hash == "" {
+			t.Errorf("prompt %s has no hash", prompt.Name)
+		}
+	}
+	if len(names) != len(promptgen.TemplateTypes) {
+		t.Errorf("status lists %v", names)
+	}
+}
+
+func TestPromptWatch(t *testing.T) {
+	s, path := newTestPromptService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Untouched files aren't reloaded
+	time.Sleep(50 * time.Millisecond)
+	if status := s.Status(); status.FailedAt != nil || keywordsPrompt(t, s) != "v1 Fed" {
+		t.Fatalf("status without changes = %+v", status)
+	}
+
+	writePromptFile(t, path, prompts("v2 {{.Title}}"))
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for keywordsPrompt(t, s) != "v2 Fed" {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() didn't reload the changed file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
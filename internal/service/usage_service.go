@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"github.com/qoentz/evedict/internal/api/dto"
+	"github.com/qoentz/evedict/internal/db/model"
+	"github.com/qoentz/evedict/internal/db/repository"
+)
+
+type UsageService struct {
+	LLMCallRepository *repository.LLMCallRepository
+}
+
+func NewUsageService(llmCallRepository *repository.LLMCallRepository) *UsageService {
+	return &UsageService{
+		LLMCallRepository: llmCallRepository,
+	}
+}
+
+func (s *UsageService) GetUsageReport(days int, runLimit int) (*dto.UsageReport, error) {
+	byDay, err := s.LLMCallRepository.GetUsageByDay(days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	byTemplate, err := s.LLMCallRepository.GetUsageByTemplate(days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	byRun, err := s.LLMCallRepository.GetUsageByRun(runLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %v", err)
+	}
+
+	return &dto.UsageReport{
+		Days:       days,
+		ByDay:      convertUsageSummaries(byDay),
+		ByTemplate: convertUsageSummaries(byTemplate),
+		ByRun:      convertUsageSummaries(byRun),
+	}, nil
+}
+
+func convertUsageSummaries(summaries []model.LLMUsageSummary) []dto.UsageSummary {
+	result := make([]dto.UsageSummary, len(summaries))
+	for i, u := range summaries {
+		result[i] = dto.UsageSummary{
+			Key:              u.Key,
+			Calls:            u.Calls,
+			Failures:         u.Failures,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			AvgLatencyMs:     u.AvgLatencyMs,
+			MaxLatencyMs:     u.MaxLatencyMs,
+			EstimatedCost:    u.EstimatedCost,
+			StartedAt:        u.StartedAt,
+		}
+	}
+	return result
+}
@@ -2,7 +2,7 @@ package util
 
 import (
 	"fmt"
-	"github.com/qoentz/evedict/internal/eventfeed/newsapi"
+	"github.com/qoentz/evedict/internal/eventfeed"
 	"strings"
 )
 
@@ -32,11 +32,11 @@ func ParseCategory(s string) (Category, error) {
 }
 
 // DetermineCategory Might be more useful than LLM
-func DetermineCategory(category newsapi.Category) Category {
+func DetermineCategory(category eventfeed.Category) Category {
 	switch category {
-	case newsapi.Business:
+	case eventfeed.Business:
 		return Economy
-	case newsapi.Entertainment, newsapi.Sports:
+	case eventfeed.Entertainment, eventfeed.Sports:
 	default:
 		return Politics
 	}